@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// After this long untouched on the menu the game starts playing itself
+const attractIdle = 30 * time.Second
+
+// How often the demo takes its next scripted step
+const attractStep = 350 * time.Millisecond
+
+// A made up target for the demo, so attract mode never scans the real disk.
+// It goes through the same scan as a real target, flags and all.
+const attractRoot = "/home/operator"
+
+var attractFS = mustMemFS(`
+/home/operator/.bash_history 8312
+/home/operator/.ssh/id_rsa 2602
+/home/operator/notes.txt 1204
+/home/operator/payroll.xlsx 48211
+/home/operator/projects/README.md 912
+/home/operator/projects/overlord.go 20480
+/home/operator/vault.kdbx 6144
+`)
+
+// attractScript is the demo run, one action per step. It loops once it gets
+// to the end.
+var attractScript = []Action{
+	ActionMoveDown, ActionSecure,
+	ActionMoveDown, ActionMoveDown, ActionMoveDown, ActionSecure,
+	ActionMoveDown, ActionMoveDown, ActionMoveUp, ActionMoveDown, ActionMoveDown, ActionSecure,
+	"", "", "", "", // hold on the finished board for a moment
+}
+
+// startAttract loads the demo target and hands the listing to the script
+func (g *Game) startAttract() {
+	g.resetFilesystem()
+	g.initalizeFilesystem(context.Background(), attractFS, attractRoot) // in memory, done straight away
+
+	g.finalFilesystemPath = attractRoot
+	g.selectedNode = 0
+	g.listOffset = 0
+	g.filter = ""
+	g.dirStack = nil
+	g.runStart = g.now()
+	g.pausedTotal = 0
+	g.attractIndex = 0
+	g.attractNext = g.now().Add(attractStep)
+	g.state = StateAttract
+}
+
+// updateAttract steps the demo along until any key takes the player back to
+// the menu
+func (g *Game) updateAttract() {
+	if g.input.AnyJustPressed() {
+		g.resetFilesystem()
+		g.returnToMenu()
+		return
+	}
+	if g.now().Before(g.attractNext) {
+		return
+	}
+	g.attractNext = g.now().Add(attractStep)
+
+	if g.attractIndex >= len(attractScript) {
+		g.startAttract()
+		return
+	}
+	switch attractScript[g.attractIndex] {
+	case ActionMoveDown:
+		g.moveSelection(1)
+	case ActionMoveUp:
+		g.moveSelection(-1)
+	case ActionSecure:
+		g.secureSelected()
+	}
+	g.attractIndex++
+}
+
+func (g *Game) drawAttract(screen *ebiten.Image) {
+	g.drawPlaying(screen)
+	if (g.now().UnixMilli()/800)%2 == 0 || g.save.Settings.ReducedMotion {
+		msg := tr("attract.banner")
+		x := (g.screenWidth - font.MeasureString(mplusNormalFont, msg).Ceil()) / 2
+		text.Draw(screen, msg, mplusNormalFont, x, g.screenHeight-g.marginY(), g.theme().Foreground)
+	}
+}
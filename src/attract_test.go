@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestAttractAfterIdle(t *testing.T) {
+	g, in := testGame(t)
+	idle := int(attractIdle / tickLength())
+
+	update(t, g, idle-5)
+	if g.state != StateMenu {
+		t.Fatalf("state %v before the idle timeout, want the menu", g.state)
+	}
+	// Any key restarts the idle clock
+	tap(t, g, in, ebiten.KeyRight)
+	update(t, g, idle-5)
+	if g.state != StateMenu {
+		t.Fatalf("state %v after input reset the idle clock, want the menu", g.state)
+	}
+	update(t, g, 10)
+	if g.state != StateAttract {
+		t.Fatalf("state %v past the idle timeout, want attract", g.state)
+	}
+
+	tap(t, g, in, ebiten.KeySpace)
+	if g.state != StateMenu {
+		t.Errorf("a key press left attract in %v, want the menu", g.state)
+	}
+}
+
+func TestAttractInDestruction(t *testing.T) {
+	g, _ := testGame(t)
+	g.currentMode = ModeDestruction
+	g.startAttract()
+	if g.usesEnergy() {
+		t.Error("the demo is paced by energy")
+	}
+	// Right up to the end of the script, before it starts over
+	for i := 0; i < 60*60 && g.attractIndex < len(attractScript); i++ {
+		update(t, g, 1)
+	}
+	if g.state != StateAttract {
+		t.Fatalf("the demo ended up in %s", g.state)
+	}
+	if slices.Contains(toastMsgs(g), tr("toast.no_energy")) {
+		t.Error("the demo ran out of energy")
+	}
+	secured := 0
+	for _, node := range g.fsNodes {
+		if node.Secured {
+			secured++
+		}
+	}
+	if secured == 0 {
+		t.Error("the demo didn't secure anything in DESTRUCTION")
+	}
+}
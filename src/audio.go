@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const sampleRate = 44100
+
+var (
+	audioContext *audio.Context
+	audioOnce    sync.Once
+
+	// The sounds are synthesized at startup rather than loaded from files,
+	// so there is nothing on disk for the binary to go looking for
+	bootBeepSound []byte
+	keyClickSound []byte
+	confirmSound  []byte
+	teletypeSound []byte
+)
+
+// initAudio creates the audio context and builds the sound effects. Ebiten
+// panics if a second context is created, so this only ever runs once.
+func initAudio() {
+	audioOnce.Do(func() {
+		audioContext = audio.NewContext(sampleRate)
+		bootBeepSound = tone(880, 60*time.Millisecond, 0.2)
+		keyClickSound = tone(2400, 8*time.Millisecond, 0.1)
+		confirmSound = append(tone(660, 70*time.Millisecond, 0.2), tone(990, 110*time.Millisecond, 0.2)...)
+		teletypeSound = tone(1600, 4*time.Millisecond, 0.05)
+	})
+}
+
+// tone renders a square wave as 16-bit stereo PCM with a short linear fade out
+// so it doesn't pop at the end
+func tone(freq float64, dur time.Duration, volume float64) []byte {
+	samples := int(dur.Seconds() * sampleRate)
+	buf := make([]byte, samples*4)
+	for i := 0; i < samples; i++ {
+		v := volume
+		if math.Sin(2*math.Pi*freq*float64(i)/sampleRate) < 0 {
+			v = -v
+		}
+		v *= 1 - float64(i)/float64(samples)
+
+		s := uint16(int16(v * math.MaxInt16))
+		binary.LittleEndian.PutUint16(buf[i*4:], s)
+		binary.LittleEndian.PutUint16(buf[i*4+2:], s)
+	}
+	return buf
+}
+
+const (
+	defaultVolume = 100
+	volumeStep    = 10 // per press in the settings
+)
+
+// setVolume sets the volume, clamped to 0..100. Turning it up or down is
+// taken as wanting sound, so it unmutes too.
+func (g *Game) setVolume(v int) {
+	g.save.Settings.Volume = min(100, max(0, v))
+	g.save.Settings.Muted = false
+}
+
+// toggleMute silences everything or brings back the volume from before
+func (g *Game) toggleMute() {
+	g.save.Settings.Muted = !g.save.Settings.Muted
+}
+
+// volume is the level sounds play at right now, 0 to 1
+func (g *Game) volume() float64 {
+	if g.save.Settings.Muted {
+		return 0
+	}
+	return float64(g.save.Settings.Volume) / 100
+}
+
+// playSound fires off a one-shot sound at the current volume
+func (g *Game) playSound(pcm []byte) {
+	volume := g.volume()
+	if volume == 0 || audioContext == nil || len(pcm) == 0 {
+		return
+	}
+	player := audioContext.NewPlayerFromBytes(pcm)
+	player.SetVolume(volume)
+	player.Play()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestVolumeAndMute(t *testing.T) {
+	g, in := testGame(t)
+	g.setVolume(40)
+	if g.save.Settings.Volume != 40 || g.volume() != 0.4 {
+		t.Fatalf("volume %d (%v), want 40", g.save.Settings.Volume, g.volume())
+	}
+	for v, want := range map[int]int{-10: 0, 250: 100} {
+		g.setVolume(v)
+		if g.save.Settings.Volume != want {
+			t.Errorf("setVolume(%d) stored %d, want %d", v, g.save.Settings.Volume, want)
+		}
+	}
+
+	g.setVolume(40)
+	tap(t, g, in, ebiten.KeyM)
+	if !g.save.Settings.Muted || g.volume() != 0 {
+		t.Fatalf("M left muted %v at volume %v", g.save.Settings.Muted, g.volume())
+	}
+	if g.save.Settings.Volume != 40 {
+		t.Errorf("muting lost the level, stored %d", g.save.Settings.Volume)
+	}
+	update(t, g, 1)
+	tap(t, g, in, ebiten.KeyM)
+	if g.save.Settings.Muted || g.volume() != 0.4 {
+		t.Errorf("unmuting gave muted %v at volume %v, want back to 0.4", g.save.Settings.Muted, g.volume())
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image/color"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+// bootFault is a scary line and the one that walks it back. They're only
+// text, nothing actually goes wrong.
+type bootFault struct {
+	error, recovery string
+}
+
+var bootFaults = []bootFault{
+	{"SEGFAULT AT 0x0000DEAD", "RECOVERED: PROCESS RESTARTED"},
+	{"ERROR: CHECKSUM MISMATCH IN SECTOR 7", "RECOVERED: USING BACKUP SUPERBLOCK"},
+	{"ERROR: WATCHDOG TIMEOUT ON CPU 3", "RECOVERED: CORE RESET"},
+	{"KERNEL PANIC - NOT SYNCING", "RECOVERED: ...PROBABLY"},
+}
+
+const bootFaultChance = 0.2 // after each line
+
+// injectBootFaults sprinkles faults between lines of a DANGER boot, drawing
+// from rng so the same seed fails in the same places. It returns the new
+// sequence and which of its indices are the error lines.
+func injectBootFaults(lines []InitSequenceBootLine, rng *rand.Rand) ([]InitSequenceBootLine, map[int]bool) {
+	out := slices.Clone(lines[:min(1, len(lines))]) // the title always comes up clean
+	errs := map[int]bool{}
+	for _, line := range lines[len(out):] {
+		if rng.Float64() < bootFaultChance {
+			fault := bootFaults[rng.Intn(len(bootFaults))]
+			errs[len(out)] = true
+			out = append(out,
+				InitSequenceBootLine{fault.error, 150, 90},
+				InitSequenceBootLine{fault.recovery, 900, 0},
+			)
+		}
+		out = append(out, line)
+	}
+	return out, errs
+}
+
+// startBoot types out the boot for the last mode played. After DANGER it
+// comes with faults.
+func (g *Game) startBoot() {
+	g.startTypewriter()
+	g.bootLines = bootSequenceFor(g.save, g.customBoot)
+	if mode, ok := g.save.lastMode(); ok && mode == ModeDanger {
+		g.bootLines, g.bootFaultLines = injectBootFaults(g.bootLines, rand.New(rand.NewSource(g.seed)))
+	}
+	g.state = StateBooting
+}
+
+// bootLineColor is the color of the boot line at index i. Fault lines flash
+// between red and normal, or sit red with reduced motion.
+func (g *Game) bootLineColor(i int) color.RGBA {
+	if !g.bootFaultLines[i] {
+		return g.terminalColor
+	}
+	if !g.save.Settings.ReducedMotion && g.now().UnixMilli()/120%2 == 0 {
+		return g.terminalColor
+	}
+	return g.theme().Warning
+}
+
+// bootLineBold is whether the typed line at index i is a warning or a fault,
+// which stand out in bold
+func (g *Game) bootLineBold(i int, line string) bool {
+	return g.bootFaultLines[i] || strings.HasPrefix(line, "WARNING")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestBootFaultsFollowSeed(t *testing.T) {
+	lines := modeBootSequences[ModeDanger]
+	for seed := range int64(20) {
+		a, aErrs := injectBootFaults(lines, rand.New(rand.NewSource(seed)))
+		b, bErrs := injectBootFaults(lines, rand.New(rand.NewSource(seed)))
+		if !reflect.DeepEqual(a, b) || !maps.Equal(aErrs, bErrs) {
+			t.Fatalf("seed %d injected different faults twice", seed)
+		}
+
+		if a[0] != lines[0] || aErrs[0] {
+			t.Errorf("seed %d: the title line got a fault", seed)
+		}
+		// Take out each error and its recovery and the original boot is left
+		var rest []InitSequenceBootLine
+		for i := 0; i < len(a); i++ {
+			if !aErrs[i] {
+				rest = append(rest, a[i])
+				continue
+			}
+			i++ // the recovery
+		}
+		if !slices.Equal(rest, lines) {
+			t.Errorf("seed %d: faults disturbed the boot itself", seed)
+		}
+	}
+}
+
+func TestDangerBootInjectsFaults(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.LastMode = modeNames[ModeDanger]
+	for g.seed = 1; g.seed < 100; g.seed++ {
+		if g.startBoot(); len(g.bootFaultLines) > 0 {
+			break
+		}
+	}
+	first, firstErrs := g.bootLines, g.bootFaultLines
+	if len(firstErrs) == 0 {
+		t.Fatal("no seed under 100 injects a fault")
+	}
+	g.startBoot()
+	if !slices.Equal(g.bootLines, first) || !maps.Equal(g.bootFaultLines, firstErrs) {
+		t.Error("rebooting with the same seed changed the faults")
+	}
+
+	// Reduced motion keeps fault lines steadily red instead of flashing
+	g.save.Settings.ReducedMotion = true
+	for i := range firstErrs {
+		for range 10 {
+			update(t, g, 1)
+			if got := g.bootLineColor(i); got != g.theme().Warning {
+				t.Fatalf("fault line %d is %v with reduced motion, want warning red", i, got)
+			}
+		}
+	}
+
+	g.save.LastMode = modeNames[ModeSafe]
+	g.startBoot()
+	if len(g.bootFaultLines) != 0 {
+		t.Errorf("a SAFE boot got %d faults", len(g.bootFaultLines))
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// bootFileLine is one entry of boot.json in the config directory, e.g.
+//
+//	[{"text": "HELLO OPERATOR", "delay": 500}, {"text": "LOADING...", "delay": 800, "chars_per_second": 10}]
+//
+// A boot.json replaces the whole boot, the per mode endings included.
+type bootFileLine struct {
+	Text           string  `json:"text"`
+	Delay          int     `json:"delay"` // ms before the line starts
+	CharsPerSecond float64 `json:"chars_per_second"`
+}
+
+// parseBootFile turns the contents of a boot.json into boot lines, refusing
+// an empty list or any entry without text or with a negative timing
+func parseBootFile(data []byte) ([]InitSequenceBootLine, error) {
+	var entries []bootFileLine
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no lines")
+	}
+	lines := make([]InitSequenceBootLine, len(entries))
+	for i, e := range entries {
+		switch {
+		case strings.TrimSpace(e.Text) == "":
+			return nil, fmt.Errorf("line %d has no text", i+1)
+		case e.Delay < 0:
+			return nil, fmt.Errorf("line %d has a negative delay", i+1)
+		case e.CharsPerSecond < 0:
+			return nil, fmt.Errorf("line %d has a negative chars_per_second", i+1)
+		}
+		lines[i] = InitSequenceBootLine{e.Text, e.Delay, e.CharsPerSecond}
+	}
+	return lines, nil
+}
+
+// loadBootFile reads the boot at path. A missing file means the built-in
+// boot, which is nil lines and no error.
+func loadBootFile(path string) ([]InitSequenceBootLine, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseBootFile(data)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseBootFile(t *testing.T) {
+	lines, err := parseBootFile([]byte(`[
+		{"text": "HELLO OPERATOR", "delay": 500},
+		{"text": "LOADING...", "delay": 0, "chars_per_second": 10}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InitSequenceBootLine{{"HELLO OPERATOR", 500, 0}, {"LOADING...", 0, 10}}
+	if !slices.Equal(lines, want) {
+		t.Errorf("parsed %+v, want %+v", lines, want)
+	}
+}
+
+func TestParseBootFileRejects(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":                `{"text": `,
+		"an object":               `{"text": "HI", "delay": 1}`,
+		"empty list":              `[]`,
+		"no text":                 `[{"delay": 100}]`,
+		"blank text":              `[{"text": "   ", "delay": 100}]`,
+		"negative delay":          `[{"text": "OK", "delay": 1}, {"text": "HI", "delay": -1}]`,
+		"negative typing speed":   `[{"text": "HI", "delay": 1, "chars_per_second": -5}]`,
+		"delay of the wrong type": `[{"text": "HI", "delay": "soon"}]`,
+	} {
+		if lines, err := parseBootFile([]byte(data)); err == nil {
+			t.Errorf("%s: accepted as %+v", name, lines)
+		}
+	}
+}
+
+func TestLoadBootFile(t *testing.T) {
+	dir := t.TempDir()
+	lines, err := loadBootFile(filepath.Join(dir, "boot.json"))
+	if lines != nil || err != nil {
+		t.Errorf("a missing boot.json gave %+v, %v, want the built-in boot", lines, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`[{"text": ""}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if lines, err := loadBootFile(bad); err == nil || lines != nil {
+		t.Errorf("a bad boot.json gave %+v, %v", lines, err)
+	}
+}
+
+func TestCustomBootReplacesBuiltIn(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.LastMode = "SAFE"
+	g.customBoot = []InitSequenceBootLine{{"MODDED", 0, 0}}
+	g.startBoot()
+	if !slices.Equal(g.bootLines, g.customBoot) {
+		t.Errorf("booting with a boot.json gave %+v", g.bootLines)
+	}
+
+	g.customBoot = nil
+	g.startBoot()
+	if len(g.bootLines) == 1 {
+		t.Error("without a boot.json the custom boot stuck around")
+	}
+}
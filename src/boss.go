@@ -0,0 +1,94 @@
+package main
+
+import (
+	"image/color"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// bossTitle replaces the window title while the boss screen is up
+const bossTitle = "Terminal"
+
+// bossScreen is a palette for the fake shell, picked in the settings
+type bossScreen struct {
+	Name       string
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+var bossScreens = []bossScreen{
+	{"DARK", color.RGBA{204, 204, 204, 255}, color.RGBA{12, 12, 12, 255}},
+	{"LIGHT", color.RGBA{40, 40, 40, 255}, color.RGBA{250, 250, 250, 255}},
+	{"SOLARIZED", color.RGBA{131, 148, 150, 255}, color.RGBA{0, 43, 54, 255}},
+}
+
+// bossOutput is what the fake shell has supposedly been doing, nothing to
+// see here. Lines starting with "$ " are commands and get the prompt instead.
+var bossOutput = []string{
+	"$ git status",
+	"On branch main",
+	"Your branch is up to date with 'origin/main'.",
+	"",
+	"nothing to commit, working tree clean",
+	"$ make test",
+	"ok      ./internal/report    0.412s",
+	"ok      ./internal/billing   1.087s",
+	"ok      ./cmd/quarterly      0.093s",
+}
+
+// bossPrompt looks like the player's own shell, or close enough
+func bossPrompt() string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("USERNAME")
+	}
+	host, err := os.Hostname()
+	if user == "" || err != nil {
+		return "$ "
+	}
+	return user + "@" + host + ":~/work$ "
+}
+
+// bossScreenIndex finds a palette by name, falling back to the first (DARK)
+func bossScreenIndex(name string) int {
+	for i, s := range bossScreens {
+		if s.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+func (g *Game) cycleBossScreen(step int) {
+	i := bossScreenIndex(g.save.Settings.BossScreen)
+	g.save.Settings.BossScreen = bossScreens[(i+step+len(bossScreens))%len(bossScreens)].Name
+}
+
+// toggleBoss swaps the game for the fake shell and back. While it's up Update
+// doesn't tick the game clock, so every timer stands still until it's gone.
+func (g *Game) toggleBoss() {
+	g.bossActive = !g.bossActive
+}
+
+// drawBoss covers the whole frame with a plain shell, no shader, glow or
+// overlay anywhere near it
+func (g *Game) drawBoss(screen *ebiten.Image) {
+	palette := bossScreens[bossScreenIndex(g.save.Settings.BossScreen)]
+	screen.Fill(palette.Background)
+
+	prompt := bossPrompt()
+	row := 0
+	for _, line := range bossOutput {
+		if cmd, ok := strings.CutPrefix(line, "$ "); ok {
+			line = prompt + cmd
+		}
+		text.Draw(screen, line, mplusNormalFont, g.marginX(), g.lineY(row), palette.Foreground)
+		row++
+	}
+	text.Draw(screen, prompt, mplusNormalFont, g.marginX(), g.lineY(row), palette.Foreground)
+	g.drawBlockCursor(screen, g.marginX()+font.MeasureString(mplusNormalFont, prompt).Ceil(), g.lineY(row), palette.Foreground)
+}
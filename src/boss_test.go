@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestBossKeyPausesDangerTimer(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeDanger, attractFS, attractRoot)
+	update(t, g, 30)
+
+	tap(t, g, in, ebiten.KeyBackquote)
+	if !g.bossActive {
+		t.Fatal("the boss key didn't bring up the fake shell")
+	}
+	before := dangerTimeRemaining(g.runElapsed())
+	update(t, g, 600)
+	if after := dangerTimeRemaining(g.runElapsed()); after != before {
+		t.Errorf("DANGER timer went from %v to %v behind the boss screen", before, after)
+	}
+	if g.windowTitle != bossTitle {
+		t.Errorf("window title is %q behind the boss screen", g.windowTitle)
+	}
+
+	tap(t, g, in, ebiten.KeyBackquote)
+	if g.bossActive {
+		t.Fatal("the boss key didn't bring the game back")
+	}
+	update(t, g, 60)
+	if after := dangerTimeRemaining(g.runElapsed()); after >= before {
+		t.Errorf("DANGER timer stuck at %v once the game was back", after)
+	}
+}
+
+func TestBossKeyOverPrompt(t *testing.T) {
+	g, in := testGame(t)
+	openPrompt(t, g, in)
+	tap(t, g, in, ebiten.KeyBackquote)
+	if !g.bossActive {
+		t.Fatal("the boss key did nothing at the prompt")
+	}
+	if g.inputBuffer != "" {
+		t.Errorf("the boss key typed %q into the prompt", g.inputBuffer)
+	}
+}
+
+func TestBossScreenCycles(t *testing.T) {
+	g, _ := testGame(t)
+	for _, want := range []string{"LIGHT", "SOLARIZED", "DARK"} {
+		g.cycleBossScreen(1)
+		if g.save.Settings.BossScreen != want {
+			t.Errorf("cycled to %q, want %q", g.save.Settings.BossScreen, want)
+		}
+	}
+	g.cycleBossScreen(-1)
+	if g.save.Settings.BossScreen != "SOLARIZED" {
+		t.Errorf("cycled back to %q", g.save.Settings.BossScreen)
+	}
+	if bossScreenIndex("NOPE") != 0 {
+		t.Error("an unknown palette doesn't fall back to DARK")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+// browseFrame is one directory the player has drilled into, along with what
+// the listing looked like before, so backing out puts the cursor back
+type browseFrame struct {
+	dir      string
+	filter   string
+	selected int
+	offset   int
+}
+
+// browseDir is the directory the listing is rooted at, empty for the whole
+// target flattened out
+func (g *Game) browseDir() string {
+	if len(g.dirStack) == 0 {
+		return ""
+	}
+	return g.dirStack[len(g.dirStack)-1].dir
+}
+
+// enterSelected re-roots the listing at the highlighted directory, showing
+// just its children. Files are ignored.
+func (g *Game) enterSelected() {
+	node, ok := g.highlighted()
+	if !ok || !node.IsDir {
+		return
+	}
+
+	g.dirStack = append(g.dirStack, browseFrame{dir: node.Path, filter: g.filter, selected: g.selectedNode, offset: g.listOffset})
+	g.filter = ""
+	g.selectedNode = 0
+	g.listOffset = 0
+}
+
+// leaveDir goes back up to the listing we came from. At the top it does nothing.
+func (g *Game) leaveDir() {
+	if len(g.dirStack) == 0 {
+		return
+	}
+	frame := g.dirStack[len(g.dirStack)-1]
+	g.dirStack = g.dirStack[:len(g.dirStack)-1]
+	g.filter = frame.filter
+	g.selectedNode = frame.selected
+	g.listOffset = frame.offset
+	g.moveSelection(0) // nodes may have been removed while we were down there
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// selectPath moves the cursor onto the listed node at path
+func selectPath(t *testing.T, g *Game, path string) {
+	t.Helper()
+	i := slices.IndexFunc(g.viewNodes(), func(n int) bool { return g.fsNodes[n].Path == path })
+	if i < 0 {
+		t.Fatalf("%s isn't in the listing", path)
+	}
+	g.moveSelection(i - g.selectedNode)
+}
+
+// listed is the base names in the current listing
+func listed(g *Game) []string {
+	var names []string
+	for _, n := range g.viewNodes() {
+		names = append(names, filepath.Base(g.fsNodes[n].Path))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestBrowseDownAndUp(t *testing.T) {
+	root := writeTree(t, map[string]string{"top.txt": "", "a/x.txt": "", "a/b/c.txt": ""})
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, diskFS{}, root)
+
+	// Backspace at the top has nowhere to go
+	tap(t, g, in, ebiten.KeyBackspace)
+	if len(g.dirStack) != 0 {
+		t.Fatalf("backspace at the root pushed %d frames", len(g.dirStack))
+	}
+
+	selectPath(t, g, filepath.Join(root, "a"))
+	topSelected := g.selectedNode
+	tap(t, g, in, ebiten.KeyEnter)
+	if g.browseDir() != filepath.Join(root, "a") {
+		t.Fatalf("browsing %q, want a", g.browseDir())
+	}
+	if got := listed(g); !slices.Equal(got, []string{"b", "x.txt"}) {
+		t.Fatalf("a lists %q", got)
+	}
+
+	selectPath(t, g, filepath.Join(root, "a", "b"))
+	tap(t, g, in, ebiten.KeyEnter)
+	if got := listed(g); !slices.Equal(got, []string{"c.txt"}) {
+		t.Fatalf("a/b lists %q", got)
+	}
+	// Enter on a file stays put
+	tap(t, g, in, ebiten.KeyEnter)
+	if len(g.dirStack) != 2 {
+		t.Fatalf("enter on a file changed the stack to %d frames", len(g.dirStack))
+	}
+
+	tap(t, g, in, ebiten.KeyBackspace)
+	if g.browseDir() != filepath.Join(root, "a") {
+		t.Fatalf("backspace from a/b went to %q, want a", g.browseDir())
+	}
+	tap(t, g, in, ebiten.KeyBackspace)
+	if len(g.dirStack) != 0 || g.selectedNode != topSelected {
+		t.Fatalf("back at the top with %d frames and cursor %d, want 0 and %d", len(g.dirStack), g.selectedNode, topSelected)
+	}
+	tap(t, g, in, ebiten.KeyBackspace)
+	if len(g.dirStack) != 0 {
+		t.Errorf("backspace past the root pushed %d frames", len(g.dirStack))
+	}
+}
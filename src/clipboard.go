@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.design/x/clipboard"
+)
+
+var (
+	clipboardOnce sync.Once
+	clipboardErr  error
+)
+
+// clipboardReady initializes the clipboard on first use. If that fails (no
+// display server, missing libraries) copy and paste are simply unavailable.
+func clipboardReady() bool {
+	clipboardOnce.Do(func() {
+		clipboardErr = clipboard.Init()
+		if clipboardErr != nil {
+			log.Println("clipboard unavailable:", clipboardErr)
+		}
+	})
+	return clipboardErr == nil
+}
+
+// readClipboard returns the text currently on the system clipboard
+func readClipboard() (string, bool) {
+	if !clipboardReady() {
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	data, err := clipboard.Read(ctx, clipboard.FmtText)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// writeClipboard puts s on the system clipboard
+func writeClipboard(s string) bool {
+	if !clipboardReady() {
+		return false
+	}
+	_, err := clipboard.Write(context.Background(), clipboard.FmtText, []byte(s))
+	return err == nil
+}
+
+// Clipboard is where yank and paste go. The game uses the system one, tests
+// swap in a fake.
+type Clipboard interface {
+	Read() (string, bool)
+	Write(s string) bool
+}
+
+// systemClipboard is the real clipboard, through golang.design/x/clipboard
+type systemClipboard struct{}
+
+func (systemClipboard) Read() (string, bool) { return readClipboard() }
+func (systemClipboard) Write(s string) bool  { return writeClipboard(s) }
+
+// sanitizePaste flattens pasted text onto one line so a multi-line paste
+// can't break the single-line prompt
+func sanitizePaste(s string) string {
+	return strings.NewReplacer("\r\n", "", "\n", "", "\r", "").Replace(s)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"/home/op", "/home/op"},
+		{"/home/op\n", "/home/op"},
+		{"/home/\r\nop", "/home/op"},
+		{"/home\r/op\n\n", "/home/op"},
+		{"with spaces\tand tabs", "with spaces\tand tabs"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := sanitizePaste(tt.in); got != tt.want {
+			t.Errorf("sanitizePaste(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// fakeClipboard holds whatever was last written to it
+type fakeClipboard struct {
+	text string
+	ok   bool
+}
+
+func (c *fakeClipboard) Read() (string, bool) { return c.text, c.ok }
+
+func (c *fakeClipboard) Write(s string) bool {
+	c.text, c.ok = s, true
+	return true
+}
+
+func TestYankCopiesPath(t *testing.T) {
+	g, in := testGame(t)
+	clip := &fakeClipboard{}
+	g.clipboard = clip
+	root := realTree(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+	startRun(t, g, ModeSafe, diskFS{}, root)
+	tap(t, g, in, ebiten.KeyDown)
+	want := g.fsNodes[g.viewNodes()[g.selectedNode]].Path
+
+	tap(t, g, in, ebiten.KeyY)
+	if clip.text != want {
+		t.Errorf("yank copied %q, want %q", clip.text, want)
+	}
+	if !filepath.IsAbs(clip.text) {
+		t.Errorf("yanked path %q isn't absolute", clip.text)
+	}
+	if len(g.toasts) == 0 || g.toasts[len(g.toasts)-1].msg != tr("playing.copied") {
+		t.Error("no COPIED toast after yanking")
+	}
+}
+
+func TestPasteFromClipboard(t *testing.T) {
+	g, in := testGame(t)
+	g.clipboard = &fakeClipboard{text: "/tmp/a\nb", ok: true}
+	openPrompt(t, g, in)
+	in.press(ebiten.KeyControl)
+	tap(t, g, in, ebiten.KeyV)
+	in.release(ebiten.KeyControl)
+	if g.inputBuffer != "/tmp/ab" {
+		t.Errorf("pasted %q, want the clipboard on one line", g.inputBuffer)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Game time doesn't follow the wall clock. It moves forward one tick per
+// Update, so when frames drop Ebiten's catch-up Updates keep it in step, and
+// when the OS stalls the game it just stops instead of jumping ahead.
+// Everything animated or timed reads it through now and since.
+
+// gameEpoch is what now returns before the first tick
+var gameEpoch = time.Unix(0, 0)
+
+// tickLength is one Update's worth of game time
+func tickLength() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS // synced to the display, assume the usual rate
+	}
+	return time.Second / time.Duration(tps)
+}
+
+// tick advances the clock by one Update
+func (g *Game) tick() {
+	g.elapsed += tickLength()
+}
+
+func (g *Game) now() time.Time {
+	return gameEpoch.Add(g.elapsed)
+}
+
+func (g *Game) since(t time.Time) time.Duration {
+	return g.now().Sub(t)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestTickLength(t *testing.T) {
+	if ebiten.TPS() != ebiten.DefaultTPS {
+		t.Skipf("running at %d TPS", ebiten.TPS())
+	}
+	second := tickLength() * time.Duration(ebiten.DefaultTPS)
+	if d := time.Second - second; d < 0 || d > time.Microsecond {
+		t.Errorf("%d ticks make %v of game time", ebiten.DefaultTPS, second)
+	}
+
+	g, _ := testGame(t)
+	start := g.now()
+	update(t, g, ebiten.DefaultTPS)
+	if d := time.Second - g.since(start); d < 0 || d > time.Microsecond {
+		t.Errorf("a second of Updates moved the clock %v", g.since(start))
+	}
+}
+
+// ticksUntil runs Updates until done holds, failing after a minute of them
+func ticksUntil(t *testing.T, g *Game, what string, done func() bool) int {
+	t.Helper()
+	for n := 0; n < 60*60; n++ {
+		if done() {
+			return n
+		}
+		update(t, g, 1)
+	}
+	t.Fatalf("never got to %s", what)
+	return 0
+}
+
+// near fails unless ticks is want, or one more for a tick length that
+// doesn't divide the interval exactly
+func near(t *testing.T, what string, ticks, want int) {
+	t.Helper()
+	if ticks < want || ticks > want+1 {
+		t.Errorf("%s took %d ticks, want %d", what, ticks, want)
+	}
+}
+
+func TestBootPacing(t *testing.T) {
+	if ebiten.TPS() != ebiten.DefaultTPS {
+		t.Skipf("running at %d TPS", ebiten.TPS())
+	}
+	g, _ := testGame(t)
+	g.customBoot = []InitSequenceBootLine{
+		{"HELLO", 500, 10},    // half a second, then 10 characters a second for 0.5s
+		{"OPERATOR", 1000, 0}, // a second, then the default 40 chars a second
+	}
+	g.startBoot()
+	line := func(i int) string {
+		if i >= len(g.bootSquenceVisibleLines) {
+			return ""
+		}
+		return g.bootSquenceVisibleLines[i]
+	}
+
+	near(t, "the first line's delay", ticksUntil(t, g, "the first line", func() bool { return len(g.bootSquenceVisibleLines) == 1 }), 30)
+	near(t, "typing HELLO", ticksUntil(t, g, "HELLO", func() bool { return line(0) == "HELLO" }), 30)
+	near(t, "the second line's delay", ticksUntil(t, g, "the second line", func() bool { return len(g.bootSquenceVisibleLines) == 2 }), 60)
+	// 8 characters at 40 a second is 0.2s
+	near(t, "typing OPERATOR", ticksUntil(t, g, "OPERATOR", func() bool { return line(1) == "OPERATOR" }), 12)
+	if g.state != StateBooting {
+		t.Fatalf("left the boot early, in %s", g.state)
+	}
+	// and then the boot holds for 2 seconds before the menu
+	near(t, "the hold before the menu", ticksUntil(t, g, "the menu", func() bool { return g.state != StateBooting }), 120)
+	if g.state != StateMenu {
+		t.Errorf("the boot ended in %s, want the menu", g.state)
+	}
+}
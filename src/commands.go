@@ -0,0 +1,332 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// How much console output is kept, and how much of it is shown above the prompt
+const (
+	consoleHistoryLines = 200
+	consoleVisibleLines = 6
+)
+
+// parseCommand splits a console line into the command and its arguments.
+// Runs of whitespace separate words, and single or double quotes group a
+// word that contains spaces. An unterminated quote runs to the end of the line.
+func parseCommand(line string) (cmd string, args []string) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	if len(words) == 0 {
+		return "", nil
+	}
+	return words[0], words[1:]
+}
+
+func (g *Game) updateConsole() {
+	if g.justPressed(ActionCancel) || g.justPressed(ActionConsole) {
+		g.commandActive = false
+		g.setInput("")
+		return
+	}
+
+	g.updateTextInput()
+
+	if g.input.JustPressed(editUp) {
+		g.recallHistory(-1)
+	}
+	if g.input.JustPressed(editDown) {
+		g.recallHistory(1)
+	}
+
+	if g.justPressed(ActionConfirm) {
+		line := g.inputBuffer
+		g.setInput("")
+		g.pushHistory(line)
+		g.runCommand(line)
+	}
+}
+
+// pushHistory remembers a command for recall, skipping blanks and repeats of
+// the previous command, and resets recall to the newest entry
+func (g *Game) pushHistory(line string) {
+	if strings.TrimSpace(line) != "" && (len(g.commandHistory) == 0 || g.commandHistory[len(g.commandHistory)-1] != line) {
+		g.commandHistory = append(g.commandHistory, line)
+	}
+	g.historyIndex = len(g.commandHistory)
+}
+
+// recallHistory steps through previous commands, -1 for older and 1 for newer.
+// Stepping past the newest entry leaves an empty prompt.
+func (g *Game) recallHistory(step int) {
+	g.historyIndex = max(0, min(g.historyIndex+step, len(g.commandHistory)))
+	if g.historyIndex == len(g.commandHistory) {
+		g.setInput("")
+		return
+	}
+	g.setInput(g.commandHistory[g.historyIndex])
+}
+
+// echo appends lines to the console output, dropping the oldest past the limit
+func (g *Game) echo(lines ...string) {
+	g.commandOutput = append(g.commandOutput, lines...)
+	if over := len(g.commandOutput) - consoleHistoryLines; over > 0 {
+		g.commandOutput = g.commandOutput[over:]
+	}
+}
+
+// echoError reports a command failing on arg, with the message for id
+func (g *Game) echoError(cmd, arg, id string) {
+	g.echo(cmd + ": " + arg + ": " + tr(id))
+}
+
+// runCommand echoes line and dispatches it to the matching handler
+func (g *Game) runCommand(line string) {
+	g.echo(g.cwd + " $ " + line)
+	cmd, args := parseCommand(line)
+	switch cmd {
+	case "":
+	case "ls":
+		g.cmdLs(args)
+	case "cd":
+		g.cmdCd(args)
+	case "cat":
+		g.cmdCat(args)
+	case "rm":
+		g.cmdRm(args)
+	case "export":
+		g.cmdExport(args)
+	default:
+		g.echo(cmd + ": " + tr("console.not_found"))
+	}
+}
+
+// resolvePath turns a console argument into a path in the scanned model,
+// relative to the console's working directory
+func (g *Game) resolvePath(arg string) string {
+	if filepath.IsAbs(arg) {
+		return filepath.Clean(arg)
+	}
+	return filepath.Join(g.cwd, arg)
+}
+
+// inTarget reports whether path is the target directory or somewhere under it
+func (g *Game) inTarget(path string) bool {
+	rel, err := filepath.Rel(g.finalFilesystemPath, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// findNode returns the index of the node at path, or -1. Caller must hold fsMutex (read or write).
+func (g *Game) findNode(path string) int {
+	for i, node := range g.fsNodes {
+		if node.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// isDirNode reports whether the model has a directory at path
+func (g *Game) isDirNode(path string) bool {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	i := g.findNode(path)
+	return i >= 0 && g.fsNodes[i].IsDir
+}
+
+func (g *Game) cmdLs(args []string) {
+	dir := g.cwd
+	if len(args) > 0 {
+		dir = g.resolvePath(args[0])
+	}
+
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	found := false
+	for _, node := range g.fsNodes {
+		if node.Path == dir || filepath.Dir(node.Path) != dir {
+			continue
+		}
+		g.echo("  " + filepath.Base(node.Path) + node.marker())
+		found = true
+	}
+	if !found {
+		g.echo("  " + tr("console.empty"))
+	}
+}
+
+func (g *Game) cmdCd(args []string) {
+	if len(args) == 0 {
+		g.cwd = g.finalFilesystemPath
+		return
+	}
+
+	dir := g.resolvePath(args[0])
+	if !g.inTarget(dir) {
+		g.echoError("cd", args[0], "console.outside_target")
+		return
+	}
+
+	if !g.isDirNode(dir) {
+		g.echoError("cd", args[0], "console.no_such_dir")
+		return
+	}
+	g.cwd = dir
+}
+
+func (g *Game) cmdCat(args []string) {
+	if len(args) == 0 {
+		g.echo(fmt.Sprintf(tr("console.usage"), "cat <file>"))
+		return
+	}
+
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	for _, arg := range args {
+		i := g.findNode(g.resolvePath(arg))
+		switch {
+		case i < 0:
+			g.echoError("cat", arg, "console.no_such_file")
+		case g.fsNodes[i].IsDir:
+			g.echoError("cat", arg, "console.is_dir")
+		case g.fsNodes[i].kind() == kindSpecial:
+			g.echoError("cat", arg, "console.special")
+		default:
+			g.echo("  " + fmt.Sprintf(tr("console.bytes"), filepath.Base(arg), g.fsNodes[i].Size))
+		}
+	}
+}
+
+// cmdRm destroys files in DESTRUCTION mode. In a dry run they're only
+// removed from the in-memory model and the file on disk is left alone.
+func (g *Game) cmdRm(args []string) {
+	if g.currentMode != ModeDestruction {
+		g.echo("rm: " + fmt.Sprintf(tr("console.mode_denied"), modeNames[g.currentMode]))
+		return
+	}
+	if len(args) == 0 {
+		g.echo(fmt.Sprintf(tr("console.usage"), "rm <file>"))
+		return
+	}
+
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	for _, arg := range args {
+		path := g.resolvePath(arg)
+		i := g.findNode(path)
+		switch {
+		case !g.inTarget(path):
+			g.echoError("rm", arg, "console.outside_target")
+		case i < 0:
+			g.echoError("rm", arg, "console.no_such_file")
+		case g.fsNodes[i].IsDir:
+			g.echoError("rm", arg, "console.is_dir")
+		case g.fsNodes[i].kind() == kindSpecial:
+			g.echoError("rm", arg, "console.special_left_alone")
+		case g.fsNodes[i].Flag || g.fsNodes[i].Dummy:
+			g.echoError("rm", arg, "console.protected")
+		case !g.spend(rmCost):
+			g.echoError("rm", arg, "toast.no_energy")
+		default:
+			if err := g.destroyFile(path); err != nil {
+				g.energy += rmCost // nothing happened, so it shouldn't cost anything
+				g.echo("rm: " + arg + ": " + describeError(err))
+				continue
+			}
+			node := g.removeNode(i)
+			g.shake(12, 300*time.Millisecond)
+			before := g.score
+			g.scoreAction()
+			if g.dryRun {
+				g.pushUndo(deletion{node, i, g.score - before})
+			}
+		}
+	}
+}
+
+// errOutsideTarget is destroyFile refusing a path outside the target
+var errOutsideTarget = errors.New("outside target")
+
+// destroyFile deletes path from disk, unless this is a dry run. It refuses
+// anything that isn't inside the target directory, so a path that climbs
+// out with .. or through a symlinked directory can never be removed.
+func (g *Game) destroyFile(path string) error {
+	if g.dryRun {
+		return nil
+	}
+	if !g.inTarget(path) {
+		return errOutsideTarget
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	if !g.inTarget(dir) {
+		return errOutsideTarget
+	}
+	return os.Remove(path)
+}
+
+// describeError turns a filesystem error into a short console message
+func describeError(err error) string {
+	switch {
+	case errors.Is(err, errOutsideTarget):
+		return tr("console.outside_target")
+	case errors.Is(err, fs.ErrPermission):
+		return tr("console.permission_denied")
+	case errors.Is(err, fs.ErrNotExist):
+		return tr("console.no_such_file")
+	}
+	return strings.ToUpper(err.Error())
+}
+
+// drawConsole draws the most recent output with the prompt underneath, at the
+// bottom of the screen
+func (g *Game) drawConsole(screen *ebiten.Image) {
+	promptRow := g.rowsFrom(0) - 1
+	start := max(0, len(g.commandOutput)-consoleVisibleLines)
+	for i, line := range g.commandOutput[start:] {
+		row := promptRow - (len(g.commandOutput) - start) + i
+		text.Draw(screen, line, mplusNormalFont, g.marginX(), g.lineY(row), g.theme().Dim)
+	}
+
+	g.drawPrompt(screen, g.cwd+" $ ", g.marginX(), g.lineY(promptRow), 0, g.terminalColor)
+}
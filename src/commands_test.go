@@ -0,0 +1,215 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		cmd  string
+		args []string
+	}{
+		{"ls", "ls", nil},
+		{"  cd   docs  ", "cd", []string{"docs"}},
+		{"rm a.txt\tb.txt", "rm", []string{"a.txt", "b.txt"}},
+		{`cat "my notes.txt"`, "cat", []string{"my notes.txt"}},
+		{`cat 'it''s'`, "cat", []string{"its"}},
+		{`rm "unterminated quote`, "rm", []string{"unterminated quote"}},
+		{`cd ""`, "cd", []string{""}},
+		{"", "", nil},
+		{"   ", "", nil},
+	}
+	for _, tt := range tests {
+		cmd, args := parseCommand(tt.line)
+		if cmd != tt.cmd || !slices.Equal(args, tt.args) {
+			t.Errorf("parseCommand(%q) = %q %q, want %q %q", tt.line, cmd, args, tt.cmd, tt.args)
+		}
+	}
+}
+
+// runConsole types each line into the console and submits it
+func runConsole(t *testing.T, g *Game, in *fakeInput, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		typeText(t, g, in, line)
+		tap(t, g, in, ebiten.KeyEnter)
+	}
+}
+
+func TestHistoryRecall(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	tap(t, g, in, ebiten.KeyTab)
+	if !g.commandActive {
+		t.Fatal("tab didn't open the console")
+	}
+	runConsole(t, g, in, "ls", "cd projects", "cd projects", "  ")
+	if want := []string{"ls", "cd projects"}; !slices.Equal(g.commandHistory, want) {
+		t.Fatalf("history %q, want %q with repeats and blanks left out", g.commandHistory, want)
+	}
+
+	steps := []struct {
+		key  ebiten.Key
+		want string
+	}{
+		{ebiten.KeyUp, "cd projects"},
+		{ebiten.KeyUp, "ls"},
+		{ebiten.KeyUp, "ls"}, // stays on the oldest
+		{ebiten.KeyDown, "cd projects"},
+		{ebiten.KeyDown, ""}, // past the newest is an empty line
+		{ebiten.KeyDown, ""},
+		{ebiten.KeyUp, "cd projects"},
+	}
+	for i, step := range steps {
+		tap(t, g, in, step.key)
+		if g.inputBuffer != step.want {
+			t.Fatalf("step %d: input %q, want %q", i, g.inputBuffer, step.want)
+		}
+	}
+}
+
+// realTree is writeTree with the symlinks resolved, the way validateTarget
+// hands a target to the scan
+func realTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(writeTree(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// victimFiles is enough files that some are left over after the flags and
+// dummies have been planted
+var victimFiles = map[string]string{
+	"a.txt": "a", "b.txt": "b", "c.txt": "c", "d.txt": "d",
+	"e.txt": "e", "f.txt": "f", "g.txt": "g", "h.txt": "h",
+}
+
+// victim is the path of a scanned file rm is allowed to destroy
+func victim(t *testing.T, g *Game) string {
+	t.Helper()
+	return g.fsNodes[nodeWhere(t, g, func(n FSNode) bool {
+		return n.kind() == kindFile && !n.Flag && !n.Dummy
+	})].Path
+}
+
+func TestDryRunLeavesFileOnDisk(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	if !g.dryRun {
+		t.Fatal("dry run is off by default")
+	}
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+
+	path := victim(t, g)
+	g.cmdRm([]string{filepath.Base(path)})
+	if g.findNode(path) >= 0 {
+		t.Errorf("rm left the node in the model: %q", g.commandOutput)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("dry run touched the file on disk: %v", err)
+	}
+}
+
+func TestInTarget(t *testing.T) {
+	g, _ := testGame(t)
+	g.finalFilesystemPath = "/home/user/target"
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/home/user/target", true},
+		{"/home/user/target/a.txt", true},
+		{"/home/user/target/sub/b.txt", true},
+		{"/home/user/target/..", false},
+		{"/home/user/other", false},
+		{"/home/user/target-2/a.txt", false},
+		{"/home/user/target/../other/a.txt", false},
+		{"/home/user/target/..hidden", true},
+	}
+	for _, tt := range tests {
+		if got := g.inTarget(tt.path); got != tt.want {
+			t.Errorf("inTarget(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRmDeletesInsideTarget(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	g.dryRun = false
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+
+	path := victim(t, g)
+	g.cmdRm([]string{filepath.Base(path)})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still on disk after rm: %v, console %q", err, g.commandOutput)
+	}
+	if g.findNode(path) >= 0 {
+		t.Error("rm left the node in the model")
+	}
+}
+
+func TestRmRefusesOutsideTarget(t *testing.T) {
+	dir := realTree(t, map[string]string{
+		"target/a.txt":      "a",
+		"outside/keep.txt":  "keep",
+		"outside/other.txt": "other",
+	})
+	root := filepath.Join(dir, "target")
+	outside := filepath.Join(dir, "outside")
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := testGame(t)
+	g.dryRun = false
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+
+	g.cmdRm([]string{"../outside/keep.txt"})
+	if out := g.commandOutput[len(g.commandOutput)-1]; !strings.Contains(out, "OUTSIDE TARGET") {
+		t.Errorf("rm through .. said %q", out)
+	}
+
+	// Inside the target by name, but the symlinked directory leads out of it
+	if err := g.destroyFile(filepath.Join(root, "link", "other.txt")); err == nil {
+		t.Error("destroyFile followed a symlink out of the target")
+	}
+	if err := g.destroyFile(filepath.Join(root, "..", "outside", "other.txt")); err == nil {
+		t.Error("destroyFile climbed out with ..")
+	}
+
+	for _, name := range []string{"keep.txt", "other.txt"} {
+		if _, err := os.Stat(filepath.Join(outside, name)); err != nil {
+			t.Errorf("%s outside the target was removed: %v", name, err)
+		}
+	}
+}
+
+func TestRmLeavesSpecialFiles(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	g.dryRun = false
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+
+	path := victim(t, g)
+	g.fsNodes[g.findNode(path)].Mode = fs.ModeNamedPipe | 0o600
+	g.cmdRm([]string{filepath.Base(path)})
+	if out := g.commandOutput[len(g.commandOutput)-1]; !strings.Contains(out, "SPECIAL FILE, LEFT ALONE") {
+		t.Errorf("rm of a pipe said %q", out)
+	}
+	if g.findNode(path) < 0 {
+		t.Error("rm dropped the special node from the model")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("rm touched the special file on disk: %v", err)
+	}
+}
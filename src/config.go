@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// configDirEnv moves everything the game writes (save, keybindings, run
+// logs) somewhere else, for portable installs and testing
+const configDirEnv = "TERMIWAR_CONFIG_DIR"
+
+var errNoConfigDir = errors.New("no config directory, set " + configDirEnv)
+
+// configDir is where the game keeps its files: $TERMIWAR_CONFIG_DIR if set,
+// otherwise termi-war inside the user's config directory. Empty when neither
+// is available.
+func configDir() string {
+	if dir := os.Getenv(configDirEnv); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "termi-war")
+}
+
+// configFile is the path of name inside the game's config directory
+func configFile(name string) (string, error) {
+	dir := configDir()
+	if dir == "" {
+		return "", errNoConfigDir
+	}
+	return filepath.Join(dir, name), nil
+}
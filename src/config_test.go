@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(configDirEnv, dir)
+	if got := configDir(); got != dir {
+		t.Errorf("configDir() = %q with %s set, want %q", got, configDirEnv, dir)
+	}
+	for _, name := range []string{"save.json", "keybindings.json", "runs"} {
+		if got, err := configFile(name); err != nil || got != filepath.Join(dir, name) {
+			t.Errorf("configFile(%q) = %q, %v, want it inside %s", name, got, err, dir)
+		}
+	}
+
+	t.Setenv(configDirEnv, "")
+	def, err := os.UserConfigDir()
+	if err != nil {
+		t.Skip("no user config directory to fall back to:", err)
+	}
+	if got := configDir(); got != filepath.Join(def, "termi-war") {
+		t.Errorf("configDir() = %q unset, want the default under %s", got, def)
+	}
+}
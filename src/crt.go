@@ -0,0 +1,58 @@
+package main
+
+import (
+	_ "embed"
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed crt.kage
+var crtShaderSource []byte
+
+var (
+	crtShader *ebiten.Shader
+
+	// shadersAvailable is false when the CRT shader wouldn't compile, and
+	// frames go out without the post-process pass instead
+	shadersAvailable bool
+)
+
+func init() {
+	initShaders(crtShaderSource)
+}
+
+// initShaders compiles the CRT shader from src, or logs why it can't and
+// leaves the post-process pass switched off
+func initShaders(src []byte) {
+	shader, err := ebiten.NewShader(src)
+	if err != nil {
+		log.Println("CRT shader unavailable, drawing without scanlines:", err)
+		crtShader, shadersAvailable = nil, false
+		return
+	}
+	crtShader, shadersAvailable = shader, true
+}
+
+// offscreenImage returns img if it already matches size, otherwise a fresh
+// image of that size, so offscreen buffers follow the window when it resizes
+func offscreenImage(img *ebiten.Image, size image.Point) *ebiten.Image {
+	if img != nil && img.Bounds().Size() == size {
+		return img
+	}
+	if img != nil {
+		img.Deallocate()
+	}
+	return ebiten.NewImage(size.X, size.Y)
+}
+
+// drawCRT draws the composed scene onto screen through the scanline shader,
+// offset by dx, dy
+func drawCRT(screen, scene *ebiten.Image, dx, dy float64) {
+	size := screen.Bounds().Size()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Translate(dx, dy)
+	op.Images[0] = scene
+	screen.DrawRectShader(size.X, size.Y, crtShader, op)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestDrawWithoutShaders(t *testing.T) {
+	if !shadersAvailable {
+		t.Fatal("the embedded CRT shader didn't compile")
+	}
+	initShaders([]byte("this is not kage"))
+	t.Cleanup(func() { initShaders(crtShaderSource) })
+	if shadersAvailable || crtShader != nil {
+		t.Fatal("a broken shader still counts as available")
+	}
+
+	g, _ := testGame(t)
+	screen := ebiten.NewImage(640, 480)
+	g.Draw(screen)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.Draw(screen)
+	if g.state == StateFault {
+		t.Fatalf("drawing without shaders faulted: %s", g.faultReport)
+	}
+}
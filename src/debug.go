@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// drawDebugOverlay prints frame rates and where the game is at in the top
+// left corner, for checking what effects like the CRT pass cost
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	msg := fmt.Sprintf("FPS: %0.1f\nTPS: %0.1f\nSTATE: %s\nMODE: %s\nSEED: %d",
+		ebiten.ActualFPS(), ebiten.ActualTPS(), g.state, modeNames[g.currentMode], g.seed)
+	ebitenutil.DebugPrintAt(screen, msg, 4, 4)
+}
@@ -0,0 +1,46 @@
+package main
+
+import "time"
+
+// shake kicks off a screen shake that starts at magnitude pixels and decays
+// to nothing over dur
+func (g *Game) shake(magnitude float64, dur time.Duration) {
+	g.shakeMagnitude = magnitude
+	g.shakeDuration = dur
+	g.shakeUntil = g.now().Add(dur)
+}
+
+// updateShake picks this tick's shake offset from the run's rng. It's done
+// per tick rather than per frame, and whether or not shake is turned off, so
+// the draws from rng, and everything after them, don't depend on the frame
+// rate or the settings.
+func (g *Game) updateShake() {
+	g.shakeX, g.shakeY = 0, 0
+	remaining := g.shakeUntil.Sub(g.now())
+	if remaining <= 0 || g.shakeDuration <= 0 {
+		return
+	}
+
+	// Ease out so the jitter settles smoothly instead of cutting off
+	t := float64(remaining) / float64(g.shakeDuration)
+	m := g.shakeMagnitude * t * t
+	g.shakeX, g.shakeY = (g.rng.Float64()*2-1)*m, (g.rng.Float64()*2-1)*m
+}
+
+// shakeOffset is how far to push this frame, zero when not shaking or when
+// the player has turned shake off or asked for reduced motion
+func (g *Game) shakeOffset() (float64, float64) {
+	if g.save.Settings.DisableShake || g.save.Settings.ReducedMotion {
+		return 0, 0
+	}
+	return g.shakeX, g.shakeY
+}
+
+// cursorVisible drives the blinking text cursor. With reduced motion the
+// cursor is always shown so it still marks the input position without flashing.
+func (g *Game) cursorVisible() bool {
+	if g.save.Settings.ReducedMotion {
+		return true
+	}
+	return (g.now().UnixMilli()/500)%2 == 0
+}
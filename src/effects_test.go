@@ -0,0 +1,85 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCursorBlinkCadence(t *testing.T) {
+	g, _ := testGame(t)
+	var flips []time.Duration
+	last := g.cursorVisible()
+	for g.elapsed = 0; g.elapsed < 3*time.Second; g.elapsed += tickLength() {
+		if v := g.cursorVisible(); v != last {
+			flips = append(flips, g.elapsed)
+			last = v
+		}
+	}
+	if len(flips) < 5 {
+		t.Fatalf("cursor flipped only at %v in 3s", flips)
+	}
+	for i := 1; i < len(flips); i++ {
+		if d := flips[i] - flips[i-1]; d < 500*time.Millisecond-tickLength() || d > 500*time.Millisecond+tickLength() {
+			t.Errorf("cursor flipped %v after the last flip, want every 500ms", d)
+		}
+	}
+}
+
+func TestCursorSteadyWithReducedMotion(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.Settings.ReducedMotion = true
+	for g.elapsed = 0; g.elapsed < 2*time.Second; g.elapsed += tickLength() {
+		if !g.cursorVisible() {
+			t.Fatalf("cursor hidden at %v with reduced motion", g.elapsed)
+		}
+	}
+}
+
+// shakeRun is the shake offsets of a seeded run over the ticks after a shake,
+// and the next number its rng would have given
+func shakeRun(t *testing.T, seed int64, disabled bool) ([][2]float64, int64) {
+	g, _ := testGame(t)
+	g.seed = seed
+	g.save.Settings.DisableShake = disabled
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.shake(12, 300*time.Millisecond)
+	var offsets [][2]float64
+	for range 10 {
+		update(t, g, 1)
+		offsets = append(offsets, [2]float64{g.shakeX, g.shakeY})
+	}
+	return offsets, g.rng.Int63()
+}
+
+func TestShakeFollowsSeed(t *testing.T) {
+	a, nextA := shakeRun(t, 42, false)
+	b, nextB := shakeRun(t, 42, false)
+	if !slices.Equal(a, b) || nextA != nextB {
+		t.Errorf("the same seed shook differently:\n%v\n%v", a, b)
+	}
+	if a[0] == ([2]float64{}) {
+		t.Error("no shake offset right after shaking")
+	}
+	if c, _ := shakeRun(t, 7, false); slices.Equal(a, c) {
+		t.Error("a different seed shook exactly the same")
+	}
+
+	// Turning shake off hides it but still takes the same numbers from rng
+	_, nextOff := shakeRun(t, 42, true)
+	if nextOff != nextA {
+		t.Error("turning shake off changed what the rng gives afterwards")
+	}
+}
+
+func TestShakeOffsetRespectsSettings(t *testing.T) {
+	g, _ := testGame(t)
+	g.shakeX, g.shakeY = 3, -4
+	if x, y := g.shakeOffset(); x != 3 || y != -4 {
+		t.Errorf("shakeOffset = %v, %v", x, y)
+	}
+	g.save.Settings.ReducedMotion = true
+	if x, y := g.shakeOffset(); x != 0 || y != 0 {
+		t.Errorf("reduced motion still shakes by %v, %v", x, y)
+	}
+}
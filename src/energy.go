@@ -0,0 +1,73 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// DESTRUCTION pacing: every action spends energy, which trickles back while
+// the run is played. Run dry and actions are refused until there's enough.
+const (
+	maxEnergy    = 100.0
+	energyPerSec = 12.0 // regenerated per second of play
+	secureCost   = 15.0
+	rmCost       = 25.0
+
+	energyBarHeight = 6
+)
+
+// regenEnergy is energy after dt of regeneration, capped at maxEnergy
+func regenEnergy(energy float64, dt time.Duration) float64 {
+	return min(maxEnergy, energy+energyPerSec*dt.Seconds())
+}
+
+// spendEnergy takes cost from energy if there's enough of it, otherwise
+// energy is left as it was and ok is false
+func spendEnergy(energy, cost float64) (float64, bool) {
+	if energy < cost {
+		return energy, false
+	}
+	return energy - cost, true
+}
+
+// usesEnergy reports whether the current run is paced by energy. The demo
+// never is, whatever mode the menu had picked.
+func (g *Game) usesEnergy() bool {
+	return g.currentMode == ModeDestruction && g.state != StateAttract
+}
+
+// updateEnergy regenerates one tick's worth, only while actually playing
+func (g *Game) updateEnergy() {
+	if g.usesEnergy() {
+		g.energy = regenEnergy(g.energy, tickLength())
+	}
+}
+
+// spend pays cost for an action, a mode without energy always can
+func (g *Game) spend(cost float64) bool {
+	if !g.usesEnergy() {
+		return true
+	}
+	energy, ok := spendEnergy(g.energy, cost)
+	g.energy = energy
+	return ok
+}
+
+// drawEnergy puts the meter under the score, as wide as a quarter of the text
+// area. It turns to the warning color once it can't pay for an rm.
+func (g *Game) drawEnergy(screen *ebiten.Image) {
+	face := getFace(titleFontSize)
+	w := float32(g.textWidth() / 4)
+	x := float32(g.screenWidth)/2 - w/2
+	y := float32(g.marginY() + face.Metrics().Height.Ceil())
+
+	theme := g.theme()
+	clr := g.terminalColor
+	if g.energy < rmCost {
+		clr = theme.Warning
+	}
+	vector.FillRect(screen, x, y, w, energyBarHeight, theme.Glow, false)
+	vector.FillRect(screen, x, y, w*float32(g.energy/maxEnergy), energyBarHeight, clr, false)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpendEnergy(t *testing.T) {
+	for _, c := range []struct {
+		energy, cost, want float64
+		ok                 bool
+	}{
+		{100, 25, 75, true},
+		{25, 25, 0, true},
+		{24.9, 25, 24.9, false},
+		{0, 15, 0, false},
+	} {
+		got, ok := spendEnergy(c.energy, c.cost)
+		if got != c.want || ok != c.ok {
+			t.Errorf("spendEnergy(%v, %v) = %v, %v, want %v, %v", c.energy, c.cost, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestRegenEnergy(t *testing.T) {
+	for _, c := range []struct {
+		energy float64
+		dt     time.Duration
+		want   float64
+	}{
+		{0, time.Second, energyPerSec},
+		{0, 500 * time.Millisecond, energyPerSec / 2},
+		{50, 0, 50},
+		{95, time.Second, maxEnergy},
+		{maxEnergy, time.Hour, maxEnergy},
+	} {
+		if got := regenEnergy(c.energy, c.dt); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("regenEnergy(%v, %v) = %v, want %v", c.energy, c.dt, got, c.want)
+		}
+	}
+}
+
+func TestDepletedEnergyBlocksRm(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+
+	g.energy = rmCost - 1
+	path := victim(t, g)
+	g.cmdRm([]string{filepath.Base(path)})
+	if out := g.commandOutput[len(g.commandOutput)-1]; !strings.Contains(out, "OUT OF ENERGY") {
+		t.Errorf("rm with no energy said %q", out)
+	}
+	if g.findNode(path) < 0 {
+		t.Fatal("rm went ahead with no energy")
+	}
+
+	// A second's play brings back more than the one short
+	update(t, g, int(time.Second/tickLength()))
+	if g.energy < rmCost {
+		t.Fatalf("energy only got back to %v", g.energy)
+	}
+	g.cmdRm([]string{filepath.Base(path)})
+	if g.findNode(path) >= 0 {
+		t.Errorf("rm refused once energy was back: %q", g.commandOutput)
+	}
+	if g.energy >= rmCost {
+		t.Errorf("rm left %v energy, it should have cost %v", g.energy, rmCost)
+	}
+}
+
+func TestSafeModeIgnoresEnergy(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.energy = 0
+	if !g.spend(rmCost) {
+		t.Error("SAFE refused an action for want of energy")
+	}
+	update(t, g, 60)
+	if g.energy != 0 {
+		t.Errorf("energy regenerated to %v outside DESTRUCTION", g.energy)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var kindNames = map[nodeKind]string{
+	kindFile:    "file",
+	kindDir:     "dir",
+	kindSymlink: "symlink",
+	kindSpecial: "special",
+}
+
+// exportNode is one node as it appears in an export. Flags are left out,
+// an export shouldn't double as a walkthrough.
+type exportNode struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Type string `json:"type"` // one of kindNames
+	Mode string `json:"mode"` // like ls -l, e.g. -rw-r--r--
+}
+
+// writeExport writes the scan of target as a JSON object with a "nodes"
+// array. Nodes are encoded one at a time, so a huge tree never has to be
+// held as a second copy in memory.
+func writeExport(w io.Writer, target string, nodes []FSNode) error {
+	bw := bufio.NewWriter(w)
+	header, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, "{\"target\": %s, \"nodes\": [\n", header)
+
+	enc := json.NewEncoder(bw)
+	for i, node := range nodes {
+		if i > 0 {
+			bw.WriteString(",")
+		}
+		if err := enc.Encode(exportNode{node.Path, node.Size, kindNames[node.kind()], node.Mode.String()}); err != nil {
+			return err
+		}
+	}
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
+
+// cmdExport saves the current model into the config directory's exports
+// folder, named by when it was taken
+func (g *Game) cmdExport(args []string) {
+	dir, err := configFile("exports")
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		g.echo("export: " + describeError(err))
+		return
+	}
+	path := filepath.Join(dir, "scan-"+time.Now().Format("20060102-150405")+".json")
+	f, err := os.Create(path)
+	if err != nil {
+		g.echo("export: " + describeError(err))
+		return
+	}
+
+	g.fsMutex.RLock()
+	count := len(g.fsNodes)
+	err = writeExport(f, g.finalFilesystemPath, g.fsNodes)
+	g.fsMutex.RUnlock()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		g.echo("export: " + describeError(err))
+		return
+	}
+	g.echo("  " + fmt.Sprintf(tr("console.exported"), count, path))
+}
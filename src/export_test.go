@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type exportFile struct {
+	Target string       `json:"target"`
+	Nodes  []exportNode `json:"nodes"`
+}
+
+func toExportNodes(nodes []FSNode) []exportNode {
+	var out []exportNode
+	for _, n := range nodes {
+		out = append(out, exportNode{n.Path, n.Size, kindNames[n.kind()], n.Mode.String()})
+	}
+	return out
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	g, _ := testGame(t)
+	scan(t, g, mustMemFS(memSpec), "/home/op")
+
+	var buf bytes.Buffer
+	if err := writeExport(&buf, "/home/op", g.fsNodes); err != nil {
+		t.Fatal(err)
+	}
+	var got exportFile
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("export isn't valid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Target != "/home/op" {
+		t.Errorf("target is %q", got.Target)
+	}
+	if want := toExportNodes(g.fsNodes); !slices.Equal(got.Nodes, want) {
+		t.Errorf("exported\n%+v\nwant\n%+v", got.Nodes, want)
+	}
+}
+
+func TestExportEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeExport(&buf, `/odd "quoted" dir`, nil); err != nil {
+		t.Fatal(err)
+	}
+	var got exportFile
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("empty export isn't valid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Target != `/odd "quoted" dir` || len(got.Nodes) != 0 {
+		t.Errorf("empty export decoded as %+v", got)
+	}
+}
+
+func TestExportCommandWritesFile(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.cmdExport(nil)
+	if out := g.commandOutput[len(g.commandOutput)-1]; !strings.Contains(out, "NODES EXPORTED") {
+		t.Fatalf("export said %q", out)
+	}
+
+	dir, err := configFile("exports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "scan-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("exports folder holds %q", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got exportFile
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Nodes) != len(g.fsNodes) {
+		t.Errorf("exported %d nodes of %d", len(got.Nodes), len(g.fsNodes))
+	}
+}
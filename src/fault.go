@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// writeCrashReport writes what's known about a panic: when, where it came
+// from, the state the game was in, the value and the stack
+func writeCrashReport(w io.Writer, at time.Time, where string, state GameState, value any, stack []byte) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "termi-war crash report\n")
+	fmt.Fprintf(bw, "time:  %s\n", at.Format(time.RFC3339))
+	fmt.Fprintf(bw, "in:    %s\n", where)
+	fmt.Fprintf(bw, "state: %s\n", state)
+	fmt.Fprintf(bw, "panic: %v\n\n", value)
+	bw.Write(stack)
+	return bw.Flush()
+}
+
+// saveCrashReport puts a crash report in the config directory's crashes
+// folder and returns its path
+func saveCrashReport(where string, state GameState, value any, stack []byte) (string, error) {
+	dir, err := configFile("crashes")
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = writeCrashReport(f, now, where, state, value, stack)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return path, err
+}
+
+// recoverFault is deferred around Update and Draw. A panic gets logged and
+// reported, and the game drops into StateFault instead of taking the whole
+// app down with it.
+func (g *Game) recoverFault(where string) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	stack := debug.Stack()
+	log.Printf("recovered from panic in %s: %v\n%s", where, value, stack)
+	path, err := saveCrashReport(where, g.state, value, stack)
+	if err != nil {
+		log.Println("failed to write crash report:", err)
+	}
+	g.fault(path)
+}
+
+// fault stops whatever was going on and shows the fault screen, report is
+// where the crash report went, empty if it couldn't be written
+func (g *Game) fault(report string) {
+	if g.cancelScan != nil {
+		g.cancelScan()
+	}
+	g.faultReport = report
+	g.commandActive = false
+	g.helpVisible = false
+	g.bossActive = false
+	g.state = StateFault
+}
+
+func (g *Game) updateFault() {
+	if g.justPressed(ActionConfirm) {
+		g.faultReport = ""
+		g.returnToMenu()
+	}
+}
+
+func (g *Game) drawFault(screen *ebiten.Image) {
+	drawBold(screen, g.warn(tr("fault.recovered")), mplusNormalFont, g.marginX(), g.lineY(0), g.theme().Warning)
+	row := 1
+	if g.faultReport != "" {
+		row += drawWrappedText(screen, fmt.Sprintf(tr("fault.report"), g.faultReport), mplusNormalFont, g.marginX(), g.lineY(row), g.textWidth(), g.theme().Dim)
+	}
+	text.Draw(screen, tr("end.return"), mplusNormalFont, g.marginX(), g.lineY(row+1), g.theme().Foreground)
+}
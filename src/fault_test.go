@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// panickyInput blows up the next time Update asks about the mouse
+type panickyInput struct {
+	*fakeInput
+	armed bool
+}
+
+func (p *panickyInput) Click() (image.Point, bool) {
+	if p.armed {
+		p.armed = false
+		panic("forced fault")
+	}
+	return p.fakeInput.Click()
+}
+
+func TestPanicInUpdateIsRecovered(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.input = &panickyInput{fakeInput: in, armed: true}
+
+	update(t, g, 1)
+	if g.state != StateFault {
+		t.Fatalf("state is %s after a panic, want FAULT", g.state)
+	}
+	if g.faultReport == "" {
+		t.Fatal("no crash report was written")
+	}
+	dir, err := configFile("crashes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(g.faultReport) != dir {
+		t.Errorf("crash report went to %s, not the crashes folder", g.faultReport)
+	}
+	data, err := os.ReadFile(g.faultReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"in:    Update", "state: PLAYING", "panic: forced fault", "panickyInput"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("crash report is missing %q:\n%s", want, data)
+		}
+	}
+
+	// The model is still usable and the game carries on from the fault screen
+	if !g.fsMutex.TryLock() {
+		t.Fatal("fsMutex is still held after the fault")
+	}
+	g.fsMutex.Unlock()
+	tap(t, g, in, ebiten.KeyEnter)
+	if g.state != StateMenu {
+		t.Errorf("confirm on the fault screen went to %s, want MENU", g.state)
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := writeCrashReport(&buf, at, "Draw", StateMenu, errors.New("nil node"), []byte("goroutine 1 [running]:\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "termi-war crash report\n" +
+		"time:  2024-03-01T12:00:00Z\n" +
+		"in:    Draw\n" +
+		"state: MENU\n" +
+		"panic: nil node\n\n" +
+		"goroutine 1 [running]:\n"
+	if buf.String() != want {
+		t.Errorf("report is\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FSNode is a single file or directory found while scanning the target
+type FSNode struct {
+	Path    string
+	Size    int64
+	IsDir   bool
+	Mode    fs.FileMode // type and permission bits from the scan, symlinks aren't followed
+	ModTime time.Time
+
+	Parent string // path of the directory holding this node
+
+	// A symlink to a directory the scan already covered, e.g. one of its own
+	// parents. Links are never followed, this just says why.
+	Loop bool
+
+	Flag    bool // one of the FLAG nodes the player has to secure
+	Dummy   bool // decoy that presents itself as a FLAG
+	Secured bool
+}
+
+// modeMarker classifies a node the way ls -F does: / for directories, @ for
+// symlinks, | for pipes, = for sockets and * for executables. Plain files
+// get nothing.
+func modeMarker(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "/"
+	case mode&fs.ModeSymlink != 0:
+		return "@"
+	case mode&fs.ModeNamedPipe != 0:
+		return "|"
+	case mode&fs.ModeSocket != 0:
+		return "="
+	case mode.IsRegular() && mode.Perm()&0o111 != 0:
+		return "*"
+	}
+	return ""
+}
+
+// nodeKind is the broad category of a node. Special files (pipes, sockets,
+// devices) can block a read or report a nonsense size, so nothing ever
+// reads or deletes them.
+type nodeKind int
+
+const (
+	kindFile nodeKind = iota
+	kindDir
+	kindSymlink
+	kindSpecial
+)
+
+// kindOf sorts file mode bits into a nodeKind
+func kindOf(mode fs.FileMode) nodeKind {
+	switch {
+	case mode.IsDir():
+		return kindDir
+	case mode&fs.ModeSymlink != 0:
+		return kindSymlink
+	case mode&(fs.ModeNamedPipe|fs.ModeSocket|fs.ModeDevice|fs.ModeCharDevice|fs.ModeIrregular) != 0:
+		return kindSpecial
+	}
+	return kindFile
+}
+
+// kind is kindOf for the node, also covering nodes made without a mode
+func (n FSNode) kind() nodeKind {
+	if n.IsDir {
+		return kindDir
+	}
+	return kindOf(n.Mode)
+}
+
+// marker is modeMarker for the node, also covering nodes made without a mode
+func (n FSNode) marker() string {
+	if n.IsDir {
+		return "/"
+	}
+	return modeMarker(n.Mode)
+}
+
+// FSStats are running totals over the nodes currently in the model
+type FSStats struct {
+	Files int
+	Dirs  int
+	Bytes int64 // size of the regular files, directories don't count
+}
+
+// add counts node in (sign 1) or back out (sign -1)
+func (s *FSStats) add(node FSNode, sign int) {
+	if node.IsDir {
+		s.Dirs += sign
+		return
+	}
+	s.Files += sign
+	s.Bytes += int64(sign) * node.Size
+}
+
+// nodesChanged marks fsNodes as having gained or lost nodes, so anything
+// built from them gets rebuilt. Caller must hold fsMutex for writing.
+func (g *Game) nodesChanged() {
+	g.fsVersion++
+}
+
+// removeNode drops the node at index i from the model and keeps the stats and
+// selection in step. Caller must hold fsMutex for writing.
+func (g *Game) removeNode(i int) FSNode {
+	node := g.fsNodes[i]
+	g.fsNodes = append(g.fsNodes[:i], g.fsNodes[i+1:]...)
+	g.nodesChanged()
+	g.fsStats.add(node, -1)
+	if view := g.viewNodes(); g.selectedNode >= len(view) {
+		g.selectedNode = max(0, len(view)-1)
+	}
+	return node
+}
+
+// humanizeBytes formats a byte count with binary units, like 1.5 KB or 3.2 GB
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	// Pick the unit after rounding, so 1023.96 KB shows as 1.0 MB and not 1024.0 KB
+	v := float64(n) / float64(div)
+	if math.Round(v*10)/10 >= unit && exp < 4 {
+		v /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", v, "KMGTP"[exp])
+}
+
+// Number of FLAG nodes planted in a scanned target
+const flagCount = 3
+
+// scanLimits bound how much of a target gets walked, so pointing the game at
+// / doesn't look like a hang. Zero means no limit.
+type scanLimits struct {
+	MaxDepth int // directory levels below the target
+	MaxNodes int
+}
+
+var defaultScanLimits = scanLimits{MaxDepth: 16, MaxNodes: 20000}
+
+// depthBelow is how many levels path sits under root, 0 for root itself
+func depthBelow(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// initalizeFilesystem walks root in fsys and records every node it finds on
+// the Game, until it's done or ctx is cancelled. It runs on its own
+// goroutine, so all writes go through fsMutex, and every write checks ctx
+// under it, so once cancel returns a stale scan can't touch the model again,
+// even after it's been reset for the next one.
+func (g *Game) initalizeFilesystem(ctx context.Context, fsys scanFS, root string) {
+	limits := g.scanLimits
+	visited := map[string]bool{} // directories walked so far, real paths since root is resolved and links aren't followed
+	err := fsys.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// The target itself being unreadable is fatal, anything below it we just skip
+			if path == root {
+				return err
+			}
+			return nil
+		}
+
+		// Past a limit we stop walking and flag the model as partial
+		if limits.MaxNodes > 0 && g.scannedNodes() >= limits.MaxNodes {
+			g.markTruncated(ctx)
+			return fs.SkipAll
+		}
+		if limits.MaxDepth > 0 && d.IsDir() && depthBelow(root, path) > limits.MaxDepth {
+			g.markTruncated(ctx)
+			return fs.SkipDir
+		}
+
+		node := FSNode{Path: path, IsDir: d.IsDir(), Mode: d.Type(), Parent: filepath.Dir(path)}
+		if info, err := d.Info(); err == nil {
+			node.Size = info.Size()
+			node.Mode = info.Mode()
+			node.ModTime = info.ModTime()
+		}
+		if node.kind() == kindSpecial {
+			node.Size = 0 // whatever a device or pipe reports isn't bytes on disk
+		}
+
+		// The walk never follows links, so a cycle can't hang it. A link back
+		// to a directory already walked (one of its own parents, say) is
+		// marked so the listing can show why it leads nowhere new.
+		if d.IsDir() {
+			visited[path] = true
+		} else if d.Type()&fs.ModeSymlink != 0 {
+			if real, err := fsys.EvalSymlinks(path); err == nil {
+				node.Loop = visited[real]
+			}
+		}
+
+		g.fsMutex.Lock()
+		defer g.fsMutex.Unlock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		g.fsNodes = append(g.fsNodes, node)
+		g.nodesChanged()
+		g.fsNodeCount++
+		g.fsStats.add(node, 1)
+		return nil
+	})
+
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	if ctx.Err() != nil {
+		return // the player backed out, nobody's waiting on this scan
+	}
+	if err != nil {
+		g.fsErr = err
+		return
+	}
+	g.placeFlags()
+	g.fsReady = true
+}
+
+func (g *Game) scannedNodes() int {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	return g.fsNodeCount
+}
+
+// scanStatus is whether the scan has failed or finished
+func (g *Game) scanStatus() (failed, ready bool) {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	return g.fsErr != nil, g.fsReady
+}
+
+func (g *Game) markTruncated(ctx context.Context) {
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	if ctx.Err() == nil {
+		g.fsTruncated = true
+	}
+}
+
+// placeFlags marks up to flagCount files as FLAG nodes, spread evenly
+// through the scan so they don't all land in the same directory, and plants a
+// dummy halfway between each pair of them wherever there is room. Special
+// files are never picked. Caller must hold fsMutex.
+func (g *Game) placeFlags() {
+	var files []int
+	for i, node := range g.fsNodes {
+		if kind := node.kind(); kind == kindFile || kind == kindSymlink {
+			files = append(files, i)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	n := min(flagCount, len(files))
+	for i := 0; i < n; i++ {
+		g.fsNodes[files[i*len(files)/n]].Flag = true
+	}
+	for i := 0; i < n; i++ {
+		node := &g.fsNodes[files[(2*i+1)*len(files)/(2*n)]]
+		if !node.Flag {
+			node.Dummy = true
+		}
+	}
+}
+
+// expandHome replaces a leading ~ with the current user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// validateTarget cleans up what the player typed and checks it names an
+// existing directory that rules don't block, returning the path to scan with
+// any symlinks resolved.
+// With allowFiles a regular file is accepted too and scans as a one node
+// filesystem.
+func validateTarget(input string, allowFiles bool, rules targetRules) (string, error) {
+	path := strings.TrimSpace(input)
+	if path == "" {
+		return "", errors.New(tr("target.empty"))
+	}
+
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	if rules.blocked(path) {
+		return "", errors.New(tr("target.blocked"))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", errors.New(tr("target.missing"))
+		}
+		return "", err
+	}
+	switch {
+	case info.IsDir():
+	case !info.Mode().IsRegular():
+		return "", errors.New(tr("target.not_dir_or_file"))
+	case !allowFiles:
+		return "", errors.New(tr("target.no_files"))
+	}
+
+	// The walk doesn't follow links, a symlinked target would scan as the
+	// link alone. Whatever it points at has to clear the rules as well.
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if rules.blocked(real) {
+		return "", errors.New(tr("target.blocked"))
+	}
+	return real, nil
+}
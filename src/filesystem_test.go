@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// writeTree makes files (relative path to contents) under a fresh temp
+// directory, creating parents as needed, and returns the directory
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// scan runs a whole scan of root on the calling goroutine
+func scan(t *testing.T, g *Game, fsys scanFS, root string) {
+	t.Helper()
+	g.resetFilesystem()
+	g.initalizeFilesystem(context.Background(), fsys, root)
+}
+
+func TestScanTempTree(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a.txt":         "hello",
+		"sub/b.txt":     "hi",
+		"sub/deep/c.go": "package c",
+	})
+	g, _ := testGame(t)
+	scan(t, g, diskFS{}, root)
+
+	if g.fsErr != nil {
+		t.Fatal(g.fsErr)
+	}
+	if !g.fsReady {
+		t.Fatal("scan finished without setting fsReady")
+	}
+	// root, a.txt, sub, sub/b.txt, sub/deep, sub/deep/c.go
+	if g.fsNodeCount != 6 || len(g.fsNodes) != 6 {
+		t.Fatalf("scanned %d nodes (%d kept), want 6", g.fsNodeCount, len(g.fsNodes))
+	}
+	if i := g.findNode(filepath.Join(root, "a.txt")); i < 0 || g.fsNodes[i].Size != 5 || g.fsNodes[i].IsDir {
+		t.Errorf("a.txt recorded wrong: %+v", g.fsNodes[i])
+	}
+	if i := g.findNode(filepath.Join(root, "sub")); i < 0 || !g.fsNodes[i].IsDir {
+		t.Error("sub isn't recorded as a directory")
+	}
+	if g.fsStats.Files != 3 || g.fsStats.Dirs != 3 || g.fsStats.Bytes != 5+2+9 {
+		t.Errorf("stats %+v, want 3 files, 3 dirs, 16 bytes", g.fsStats)
+	}
+}
+
+func TestScanMissingTarget(t *testing.T) {
+	g, _ := testGame(t)
+	scan(t, g, diskFS{}, filepath.Join(t.TempDir(), "nope"))
+	if g.fsErr == nil {
+		t.Fatal("scanning a missing directory didn't record an error")
+	}
+	if g.fsReady {
+		t.Fatal("a failed scan still reported ready")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := []struct{ in, want string }{
+		{"~", home},
+		{"~/projects", filepath.Join(home, "projects")},
+		{"/tmp/~", "/tmp/~"},
+		{"~other/x", "~other/x"},
+		{"relative", "relative"},
+	}
+	for _, tt := range tests {
+		got, err := expandHome(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("expandHome(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	// resolved up front, the temp directory may itself sit behind a link
+	home, err := filepath.EvalSymlinks(writeTree(t, map[string]string{"file.txt": "x", "dir/inner.txt": "y"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(filepath.Join(home, "dir"), link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string // empty means rejected
+	}{
+		{"  ~/dir  ", filepath.Join(home, "dir")},
+		{home, home},
+		{"", ""},
+		{"   ", ""},
+		{filepath.Join(home, "missing"), ""},
+		{filepath.Join(home, "file.txt"), ""}, // file targets are off
+		{link, filepath.Join(home, "dir")},    // scanned through the link
+	}
+	for _, tt := range tests {
+		got, err := validateTarget(tt.in, false, targetRules{})
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("validateTarget(%q) accepted %q", tt.in, got)
+		case tt.want != "" && (err != nil || got != tt.want):
+			t.Errorf("validateTarget(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateTargetSymlinkScansTarget(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.txt": "1", "b.txt": "2"})
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	path, err := validateTarget(link, false, targetRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := testGame(t)
+	scan(t, g, diskFS{}, path)
+	if g.fsNodeCount != 3 {
+		t.Fatalf("scanning through a symlinked target found %d nodes, want 3", g.fsNodeCount)
+	}
+}
+
+// TestScanWhileDrawing runs a real background scan while the main loop keeps
+// updating and drawing over it. It's meant for go test -race, which catches
+// any read of the model that skips fsMutex.
+func TestScanWhileDrawing(t *testing.T) {
+	files := map[string]string{}
+	for i := range 200 {
+		files[fmt.Sprintf("d%d/f%d.txt", i%10, i)] = "data"
+	}
+	root := writeTree(t, files)
+	g, _ := testGame(t)
+	g.currentMode = ModeSafe
+	g.engage(root)
+	g.state = StateFSInit
+
+	screen := ebiten.NewImage(640, 480)
+	deadline := time.Now().Add(10 * time.Second)
+	for g.state == StateFSInit && time.Now().Before(deadline) {
+		update(t, g, 1)
+		g.Draw(screen)
+		time.Sleep(time.Millisecond) // a frame's worth of idle, lets the scan run
+	}
+	if g.state != StatePlaying {
+		t.Fatalf("state %v after scanning, want playing", g.state)
+	}
+	if got := len(g.viewNodes()); got == 0 {
+		t.Error("finished scan shows an empty listing")
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{1048575, "1.0 MB"}, // rounds up to the next unit, not 1024.0 KB
+		{1 << 20, "1.0 MB"},
+		{5 << 20, "5.0 MB"},
+		{1073741823, "1.0 GB"},
+		{1 << 30, "1.0 GB"},
+		{3 << 29, "1.5 GB"},
+		{1 << 40, "1.0 TB"},
+	}
+	for _, tt := range tests {
+		if got := humanizeBytes(tt.n); got != tt.want {
+			t.Errorf("humanizeBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestModeMarker(t *testing.T) {
+	tests := []struct {
+		mode fs.FileMode
+		want string
+		kind nodeKind
+	}{
+		{0o644, "", kindFile},
+		{0o755, "*", kindFile},
+		{0o100, "*", kindFile},
+		{fs.ModeDir | 0o755, "/", kindDir},
+		{fs.ModeSymlink | 0o777, "@", kindSymlink},
+		{fs.ModeNamedPipe | 0o644, "|", kindSpecial},
+		{fs.ModeSocket | 0o755, "=", kindSpecial},
+		{fs.ModeDevice | fs.ModeCharDevice | 0o666, "", kindSpecial},
+	}
+	for _, tt := range tests {
+		if got := modeMarker(tt.mode); got != tt.want {
+			t.Errorf("modeMarker(%v) = %q, want %q", tt.mode, got, tt.want)
+		}
+		if got := kindOf(tt.mode); got != tt.kind {
+			t.Errorf("kindOf(%v) = %v, want %v", tt.mode, got, tt.kind)
+		}
+	}
+}
+
+func TestScanRecordsMarkers(t *testing.T) {
+	root := writeTree(t, map[string]string{"plain.txt": "x", "run.sh": "#!/bin/sh", "dir/inner.txt": "y"})
+	if err := os.Chmod(filepath.Join(root, "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("plain.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := testGame(t)
+	scan(t, g, diskFS{}, root)
+
+	for name, want := range map[string]string{"plain.txt": "", "run.sh": "*", "dir": "/", "link": "@"} {
+		i := g.findNode(filepath.Join(root, name))
+		if i < 0 {
+			t.Errorf("%s wasn't scanned", name)
+			continue
+		}
+		if got := g.fsNodes[i].marker(); got != want {
+			t.Errorf("%s marked %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestScanDepthLimit(t *testing.T) {
+	root := writeTree(t, map[string]string{"a/b/c/d/deep.txt": "x", "top.txt": "y"})
+	g, _ := testGame(t)
+	g.scanLimits = scanLimits{MaxDepth: 2}
+	scan(t, g, diskFS{}, root)
+
+	if !g.fsReady || !g.fsTruncated {
+		t.Fatalf("ready %v truncated %v, want a finished but partial scan", g.fsReady, g.fsTruncated)
+	}
+	for _, path := range []string{"top.txt", "a", "a/b"} {
+		if g.findNode(filepath.Join(root, path)) < 0 {
+			t.Errorf("%s is within the limit but wasn't scanned", path)
+		}
+	}
+	for _, path := range []string{"a/b/c", "a/b/c/d/deep.txt"} {
+		if g.findNode(filepath.Join(root, path)) >= 0 {
+			t.Errorf("%s is past the depth limit but was scanned", path)
+		}
+	}
+}
+
+func TestScanCountLimit(t *testing.T) {
+	files := map[string]string{}
+	for i := range 50 {
+		files[fmt.Sprintf("d%d/f%d.txt", i%5, i)] = "x"
+	}
+	root := writeTree(t, files)
+	g, _ := testGame(t)
+	g.scanLimits = scanLimits{MaxNodes: 10}
+	scan(t, g, diskFS{}, root)
+
+	if !g.fsReady || !g.fsTruncated {
+		t.Fatalf("ready %v truncated %v, want a finished but partial scan", g.fsReady, g.fsTruncated)
+	}
+	if g.fsNodeCount != 10 {
+		t.Errorf("scanned %d nodes, want the limit of 10", g.fsNodeCount)
+	}
+
+	// Without limits the same tree scans in full
+	g.scanLimits = scanLimits{}
+	scan(t, g, diskFS{}, root)
+	if g.fsTruncated || g.fsNodeCount != 1+5+50 {
+		t.Errorf("unlimited scan: %d nodes, truncated %v, want all 56", g.fsNodeCount, g.fsTruncated)
+	}
+}
+
+// cancellingFS walks a memFS and cancels the scan's context on the stopAt'th
+// node, counting how many nodes the walk hands out
+type cancellingFS struct {
+	*memFS
+	stopAt int
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (c *cancellingFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return c.memFS.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		c.calls++
+		if c.calls == c.stopAt {
+			c.cancel()
+		}
+		return fn(path, d, err)
+	})
+}
+
+func TestCancelStopsScan(t *testing.T) {
+	var spec strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&spec, "/big/f%04d 10\n", i)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fsys := &cancellingFS{memFS: mustMemFS(spec.String()), stopAt: 5, cancel: cancel}
+	g, _ := testGame(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.initalizeFilesystem(ctx, fsys, "/big")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled scan never returned")
+	}
+
+	if fsys.calls != fsys.stopAt {
+		t.Errorf("walk visited %d nodes, want it to stop at %d", fsys.calls, fsys.stopAt)
+	}
+	if len(g.fsNodes) != fsys.stopAt-1 {
+		t.Errorf("model has %d nodes, want the %d from before the cancel", len(g.fsNodes), fsys.stopAt-1)
+	}
+	if g.fsReady || g.fsErr != nil {
+		t.Errorf("cancelled scan left ready %v, err %v", g.fsReady, g.fsErr)
+	}
+}
+
+func TestEscapeCancelsScan(t *testing.T) {
+	g, in := testGame(t)
+	g.engage(writeTree(t, map[string]string{"a.txt": "a"}))
+	g.state = StateFSInit
+	tap(t, g, in, ebiten.KeyEscape)
+	if g.state != StateMenu {
+		t.Fatalf("escape during the scan went to %v, want the menu", g.state)
+	}
+	time.Sleep(50 * time.Millisecond) // let the cancelled walk wind down
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	if g.fsReady || len(g.fsNodes) != 0 {
+		t.Errorf("the cancelled scan still wrote to the model: ready %v, %d nodes", g.fsReady, len(g.fsNodes))
+	}
+}
+
+func TestFileTargets(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(writeTree(t, map[string]string{"only.txt": "hello"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "only.txt")
+
+	_, err = validateTarget(file, false, targetRules{})
+	if err == nil || !strings.Contains(err.Error(), "NOT A DIRECTORY") {
+		t.Errorf("file target with the setting off: %v, want a NOT A DIRECTORY rejection", err)
+	}
+
+	path, err := validateTarget(file, true, targetRules{})
+	if err != nil || path != file {
+		t.Fatalf("file target with the setting on: %q, %v", path, err)
+	}
+	g, _ := testGame(t)
+	scan(t, g, diskFS{}, path)
+	if !g.fsReady || g.fsErr != nil {
+		t.Fatalf("scanning a single file: ready %v err %v", g.fsReady, g.fsErr)
+	}
+	if len(g.fsNodes) != 1 || g.fsNodes[0].Path != file || g.fsNodes[0].Size != 5 {
+		t.Errorf("single file scanned as %+v, want just only.txt", g.fsNodes)
+	}
+}
+
+func TestScanSymlinkLoop(t *testing.T) {
+	root, err := filepath.EvalSymlinks(writeTree(t, map[string]string{"sub/a.txt": "a"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		"self":       ".",       // the target itself
+		"sub/parent": "..",      // a parent of the link
+		"sub/me":     ".",       // the directory holding the link
+		"ahead":      "sub",     // somewhere not yet walked, not a loop
+		"dangling":   "missing", // nowhere at all
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, _ := testGame(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scan(t, g, diskFS{}, root)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan of a symlink loop never finished")
+	}
+	if !g.fsReady || g.fsErr != nil {
+		t.Fatalf("ready %v err %v", g.fsReady, g.fsErr)
+	}
+	// root, sub, sub/a.txt and the five links, nothing walked twice
+	if g.fsNodeCount != 8 {
+		t.Errorf("scanned %d nodes, want 8", g.fsNodeCount)
+	}
+	for link, loop := range map[string]bool{"self": true, "sub/parent": true, "sub/me": true, "ahead": false, "dangling": false} {
+		i := g.findNode(filepath.Join(root, link))
+		if i < 0 {
+			t.Errorf("link %s wasn't scanned", link)
+		} else if g.fsNodes[i].Loop != loop {
+			t.Errorf("link %s loop %v, want %v", link, g.fsNodes[i].Loop, loop)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	for _, c := range []struct {
+		mode fs.FileMode
+		want nodeKind
+	}{
+		{0o644, kindFile},
+		{fs.ModeDir | 0o755, kindDir},
+		{fs.ModeSymlink | 0o777, kindSymlink},
+		{fs.ModeNamedPipe | 0o600, kindSpecial},
+		{fs.ModeSocket | 0o755, kindSpecial},
+		{fs.ModeDevice | 0o660, kindSpecial},
+		{fs.ModeDevice | fs.ModeCharDevice | 0o666, kindSpecial},
+		{fs.ModeIrregular, kindSpecial},
+	} {
+		if got := kindOf(c.mode); got != c.want {
+			t.Errorf("kindOf(%v) = %v, want %v", c.mode, got, c.want)
+		}
+	}
+}
+
+func TestScanSpecialFile(t *testing.T) {
+	fsys := mustMemFS("/t/fifo 4096\n/t/a.txt 3")
+	e := fsys.entries["/t/fifo"]
+	e.mode = fs.ModeNamedPipe | 0o600
+	fsys.entries["/t/fifo"] = e
+
+	g, _ := testGame(t)
+	scan(t, g, fsys, "/t")
+	i := g.findNode("/t/fifo")
+	if i < 0 {
+		t.Fatal("the pipe wasn't scanned")
+	}
+	if n := g.fsNodes[i]; n.kind() != kindSpecial || n.Size != 0 {
+		t.Errorf("pipe scanned as kind %v with size %d", n.kind(), n.Size)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+// Face sizes used around the game
+const (
+	normalFontSize = 30
+	titleFontSize  = 48
+)
+
+var (
+	vt323Font *opentype.Font // parsed once from the embedded TTF, nil if that failed
+	fontErr   error          // why the font couldn't be loaded, shown on screen
+
+	faceMutex sync.Mutex
+	faceCache = map[int]font.Face{}
+)
+
+// loadFont parses TTF data, returning an error instead of exiting so the
+// game can carry on with a fallback face
+func loadFont(data []byte) (*opentype.Font, error) {
+	tt, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure a face can actually be built from it before trusting it
+	face, err := newFace(tt, normalFontSize)
+	if err != nil {
+		return nil, err
+	}
+	face.Close()
+	return tt, nil
+}
+
+func newFace(tt *opentype.Font, size int) (font.Face, error) {
+	const dpi = 72
+	return opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    float64(size),
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+}
+
+// getFace returns the VT323 face at size, building it the first time it's
+// asked for and reusing it after that. Without a usable font every size gets
+// the tiny built-in basicfont face.
+func getFace(size int) font.Face {
+	faceMutex.Lock()
+	defer faceMutex.Unlock()
+
+	if face, ok := faceCache[size]; ok {
+		return face
+	}
+
+	var face font.Face = basicfont.Face7x13
+	if vt323Font != nil {
+		f, err := newFace(vt323Font, size)
+		if err != nil {
+			log.Println("failed to build font face, falling back:", err)
+		} else {
+			face = f
+		}
+	}
+	faceCache[size] = face
+	return face
+}
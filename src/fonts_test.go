@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestGetFaceCaches(t *testing.T) {
+	first := getFace(titleFontSize)
+	if first == nil {
+		t.Fatal("no face at the title size")
+	}
+	if again := getFace(titleFontSize); again != first {
+		t.Error("asking for the same size twice built a second face")
+	}
+	if vt323Font != nil && getFace(normalFontSize) == first {
+		t.Error("two sizes share one face")
+	}
+}
+
+func TestLoadFont(t *testing.T) {
+	if _, err := loadFont(vt323FontData); err != nil {
+		t.Fatalf("embedded font: %v", err)
+	}
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"garbage":   []byte("definitely not a font"),
+		"truncated": vt323FontData[:len(vt323FontData)/8],
+	} {
+		if tt, err := loadFont(data); err == nil || tt != nil {
+			t.Errorf("%s: loadFont returned %v, %v, want an error", name, tt, err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// frameRates are the choices for Settings.FrameRate. Game time advances by
+// each tick's share of the TPS (see tick), so capping it makes the game
+// choppier but never slower.
+var frameRates = []string{"60", "30", "UNLIMITED"}
+
+// frameRateConfig is the TPS and vsync for a frame rate setting. Empty or
+// unknown means 60. UNLIMITED keeps the default TPS and just stops waiting
+// on the display to draw.
+func frameRateConfig(rate string) (tps int, vsync bool) {
+	switch rate {
+	case "30":
+		return 30, true
+	case "UNLIMITED":
+		return ebiten.DefaultTPS, false
+	}
+	return 60, true
+}
+
+// applyFrameRate hands the current frame rate setting to Ebiten
+func (g *Game) applyFrameRate() {
+	tps, vsync := frameRateConfig(g.save.Settings.FrameRate)
+	ebiten.SetTPS(tps)
+	ebiten.SetVsyncEnabled(vsync)
+}
+
+// cycleFrameRate steps through frameRates and applies the new one right away
+func (g *Game) cycleFrameRate(step int) {
+	i := 0
+	for j, rate := range frameRates {
+		if rate == g.save.Settings.FrameRate {
+			i = j
+		}
+	}
+	g.save.Settings.FrameRate = frameRates[(i+step+len(frameRates))%len(frameRates)]
+	g.applyFrameRate()
+}
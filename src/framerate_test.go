@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestFrameRateConfig(t *testing.T) {
+	tests := []struct {
+		rate  string
+		tps   int
+		vsync bool
+	}{
+		{"", 60, true},
+		{"60", 60, true},
+		{"30", 30, true},
+		{"UNLIMITED", ebiten.DefaultTPS, false},
+		{"bogus", 60, true},
+	}
+	for _, tt := range tests {
+		if tps, vsync := frameRateConfig(tt.rate); tps != tt.tps || vsync != tt.vsync {
+			t.Errorf("frameRateConfig(%q) = %d, %v, want %d, %v", tt.rate, tps, vsync, tt.tps, tt.vsync)
+		}
+	}
+}
+
+func TestFrameRateKeepsGameSpeed(t *testing.T) {
+	g, _ := testGame(t)
+	t.Cleanup(func() { ebiten.SetTPS(ebiten.DefaultTPS) })
+
+	g.cycleFrameRate(1)
+	if g.save.Settings.FrameRate != "30" || ebiten.TPS() != 30 {
+		t.Fatalf("cycled to %q with TPS %d, want 30 for both", g.save.Settings.FrameRate, ebiten.TPS())
+	}
+	// A second is still a second, there are just fewer ticks in it
+	start := g.now()
+	update(t, g, 30)
+	if got := g.since(start); got.Round(time.Millisecond) != time.Second {
+		t.Errorf("30 ticks at 30 TPS moved the clock %v, want 1s", got)
+	}
+}
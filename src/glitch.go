@@ -0,0 +1,100 @@
+package main
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Odds of a glitch starting on any given tick, and how long one lasts
+const (
+	glitchChance    = 1.0 / 240
+	glitchMinFrames = 3
+	glitchMaxFrames = 8
+)
+
+// glitchSlice is a horizontal band of the screen pushed sideways. y and h
+// are fractions of the screen height so a glitch survives a resize.
+type glitchSlice struct {
+	y, h float64
+	dx   float64
+}
+
+// glitchState drives DANGER mode's corruption effect. All the randomness is
+// drawn in update from rng, so the same seed always glitches the same way.
+type glitchState struct {
+	rng         *rand.Rand
+	framesLeft  int
+	slices      []glitchSlice
+	flickerSeed int64 // reseeds the character flicker so Draw stays deterministic
+}
+
+func newGlitchState(seed int64) *glitchState {
+	return &glitchState{rng: rand.New(rand.NewSource(seed))}
+}
+
+// update advances one tick, maybe starting a new glitch if enabled
+func (gl *glitchState) update(enabled bool) {
+	if gl.framesLeft > 0 {
+		gl.framesLeft--
+		return
+	}
+	gl.slices = nil
+	if !enabled || gl.rng.Float64() >= glitchChance {
+		return
+	}
+
+	gl.framesLeft = glitchMinFrames + gl.rng.Intn(glitchMaxFrames-glitchMinFrames+1)
+	gl.flickerSeed = gl.rng.Int63()
+	for n := 2 + gl.rng.Intn(3); n > 0; n-- {
+		dx := 5 + gl.rng.Float64()*25
+		if gl.rng.Intn(2) == 0 {
+			dx = -dx
+		}
+		gl.slices = append(gl.slices, glitchSlice{y: gl.rng.Float64(), h: 0.01 + gl.rng.Float64()*0.04, dx: dx})
+	}
+}
+
+func (gl *glitchState) active() bool {
+	return gl != nil && gl.framesLeft > 0
+}
+
+// glitchEnabled is whether DANGER mode's glitches should be running right now
+func (g *Game) glitchEnabled() bool {
+	return g.state == StatePlaying && g.currentMode == ModeDanger && !g.save.Settings.ReducedMotion
+}
+
+// apply copies scene into dst with the glitch's slices shifted sideways
+func (gl *glitchState) apply(dst, scene *ebiten.Image) {
+	dst.DrawImage(scene, nil)
+	size := scene.Bounds().Size()
+	for _, s := range gl.slices {
+		y0 := int(s.y * float64(size.Y))
+		y1 := min(size.Y, y0+max(1, int(s.h*float64(size.Y))))
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(s.dx, float64(y0))
+		dst.DrawImage(scene.SubImage(image.Rect(0, y0, size.X, y1)).(*ebiten.Image), op)
+	}
+}
+
+// glitchGlyphs are what flickering characters get swapped for
+var glitchGlyphs = []rune("#%&@$?!<>/\\|*+=01")
+
+// flicker corrupts a few characters of s while a glitch is running. Each
+// line gets its own stream from flickerSeed so the result doesn't depend on
+// how many times Draw runs.
+func (gl *glitchState) flicker(s string, line int) string {
+	if !gl.active() || len(s) == 0 {
+		return s
+	}
+	rng := rand.New(rand.NewSource(gl.flickerSeed + int64(line)))
+	if rng.Intn(3) != 0 {
+		return s // most lines are left readable
+	}
+	runes := []rune(s)
+	for n := 1 + rng.Intn(2); n > 0; n-- {
+		runes[rng.Intn(len(runes))] = glitchGlyphs[rng.Intn(len(glitchGlyphs))]
+	}
+	return string(runes)
+}
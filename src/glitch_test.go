@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// glitchRun starts a run with seed and records every frame's glitch slices
+func glitchRun(t *testing.T, seed int64, frames int) [][]glitchSlice {
+	t.Helper()
+	g, _ := testGame(t)
+	g.seed = seed
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	var seen [][]glitchSlice
+	for range frames {
+		g.glitch.update(true)
+		seen = append(seen, g.glitch.slices)
+	}
+	return seen
+}
+
+func TestSameSeedSameGlitches(t *testing.T) {
+	const frames = 2000
+	a, b := glitchRun(t, 42, frames), glitchRun(t, 42, frames)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("two runs with seed 42 glitched differently")
+	}
+	if reflect.DeepEqual(a, glitchRun(t, 43, frames)) {
+		t.Error("seeds 42 and 43 glitched exactly the same")
+	}
+}
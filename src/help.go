@@ -0,0 +1,113 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+)
+
+// helpEntry is one row of the help overlay, an action and the message id
+// saying what it does
+type helpEntry struct {
+	action Action
+	desc   string
+}
+
+// Every screen gets these on top of its own
+var globalHelp = []helpEntry{
+	{ActionHelp, "help.help"},
+	{ActionMute, "help.mute"},
+	{ActionFullscreen, "help.fullscreen"},
+	{ActionDebug, "help.debug"},
+	{ActionBoss, "help.boss"},
+}
+
+var stateHelp = map[GameState][]helpEntry{
+	StateMenu: {
+		{ActionMoveLeft, "help.mode_prev"},
+		{ActionMoveRight, "help.mode_next"},
+		{ActionConfirm, "help.mode_select"},
+		{ActionSettings, "help.settings"},
+		{ActionReboot, "help.reboot"},
+		{ActionResume, "help.resume_run"},
+		{ActionQuit, "help.quit"},
+	},
+	StatePlaying: {
+		{ActionMoveUp, "help.cursor_up"},
+		{ActionMoveDown, "help.cursor_down"},
+		{ActionPageUp, "help.page_up"},
+		{ActionPageDown, "help.page_down"},
+		{ActionSecure, "help.secure"},
+		{ActionConfirm, "help.enter_dir"},
+		{ActionDelete, "help.leave_dir"},
+		{ActionSearch, "help.search"},
+		{ActionYank, "help.yank"},
+		{ActionSort, "help.sort"},
+		{ActionConsole, "help.console"},
+		{ActionPause, "help.pause"},
+	},
+	StateSettings: {
+		{ActionMoveUp, "help.cursor_up"},
+		{ActionMoveDown, "help.cursor_down"},
+		{ActionMoveRight, "help.setting_change"},
+		{ActionCancel, "help.settings_back"},
+	},
+	StatePaused: {
+		{ActionPause, "help.resume"},
+		{ActionAbandon, "help.abandon"},
+	},
+}
+
+// helpPressed reports whether the help key was hit, F1 by default or a typed
+// ? anywhere the player isn't typing text
+func (g *Game) helpPressed() bool {
+	return g.justPressed(ActionHelp) || (!g.typing() && slices.Contains(g.input.Chars(), '?'))
+}
+
+// helpLines is the overlay text for the current screen, read from the keymap
+// so rebinding a key shows up here too
+func (g *Game) helpLines() []string {
+	entries := append(slices.Clone(stateHelp[g.state]), globalHelp...)
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(strings.ToUpper(g.keymap[e.action].String())))
+	}
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		key := strings.ToUpper(g.keymap[e.action].String())
+		lines = append(lines, key+strings.Repeat(" ", width-len(key)+2)+tr(e.desc))
+	}
+	return lines
+}
+
+// drawHelp draws the controls in a dark panel over whatever is on screen
+func (g *Game) drawHelp(screen *ebiten.Image) {
+	g.drawPanel(screen, append([]string{tr("help.title"), ""}, g.helpLines()...))
+}
+
+// drawPanel draws lines centered in a dark box, the first one as a heading
+func (g *Game) drawPanel(screen *ebiten.Image, lines []string) {
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(mplusNormalFont, line).Ceil())
+	}
+	pad := g.marginX() * 2
+	w, h := width+pad*2, len(lines)*lineHeight()+pad*2
+	x, y := (g.screenWidth-w)/2, (g.screenHeight-h)/2
+
+	vector.FillRect(screen, float32(x), float32(y), float32(w), float32(h), color.RGBA{0, 0, 0, 220}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 2, g.theme().Dim, false)
+	ascent := mplusNormalFont.Metrics().Ascent.Ceil()
+	for i, line := range lines {
+		clr := g.theme().Foreground
+		if i > 0 {
+			clr = g.theme().Dim
+		}
+		text.Draw(screen, line, mplusNormalFont, x+pad, y+pad+ascent+i*lineHeight(), clr)
+	}
+}
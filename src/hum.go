@@ -0,0 +1,70 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Background hum levels, picked in the settings
+var humLevels = []string{"OFF", "LOW", "HIGH"}
+
+// humIntensity is how strong the hum is drawn, 0 when it's off. Unset saves
+// get LOW.
+func (g *Game) humIntensity() float64 {
+	if g.save.Settings.ReducedMotion {
+		return 0
+	}
+	switch g.save.Settings.Hum {
+	case "OFF":
+		return 0
+	case "HIGH":
+		return 1
+	}
+	return 0.5
+}
+
+func (g *Game) cycleHum(step int) {
+	i := 1
+	for j, level := range humLevels {
+		if level == g.save.Settings.Hum {
+			i = j
+		}
+	}
+	g.save.Settings.Hum = humLevels[(i+step+len(humLevels))%len(humLevels)]
+}
+
+// scaleColor fades c towards transparent, a is 0..1
+func scaleColor(c color.RGBA, a float64) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * a), uint8(float64(c.G) * a), uint8(float64(c.B) * a), uint8(255 * a)}
+}
+
+// drawHum paints a faint grid that slowly pulses, plus a glow band that
+// sweeps down the screen, so the terminal looks alive. It's drawn straight
+// after the background fill so all the text sits on top of it.
+func (g *Game) drawHum(screen *ebiten.Image) {
+	intensity := g.humIntensity()
+	if intensity == 0 {
+		return
+	}
+
+	glow := g.theme().Glow
+	now := float64(g.now().UnixMilli()) / 1000
+	pulse := 0.6 + 0.4*math.Sin(now*2*math.Pi/4) // one slow breath every 4s
+
+	const spacing = 60
+	w, h := float32(g.screenWidth), float32(g.screenHeight)
+	gridColor := scaleColor(glow, 0.5*pulse*intensity)
+	for x := float32(0); x < w; x += spacing {
+		vector.FillRect(screen, x, 0, 1, h, gridColor, false)
+	}
+	for y := float32(0); y < h; y += spacing {
+		vector.FillRect(screen, 0, y, w, 1, gridColor, false)
+	}
+
+	// Sweep takes 6s top to bottom
+	sweepY := float32(math.Mod(now/6, 1)) * h
+	vector.FillRect(screen, 0, sweepY, w, h/40, scaleColor(glow, 0.6*intensity), false)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"image"
+	"slices"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource is where Update reads the keyboard from. Normally that's
+// Ebiten, but a replay can stand in for it.
+type InputSource interface {
+	// Tick is called once at the start of every Update, before any reads
+	Tick()
+	JustPressed(key ebiten.Key) bool
+	Pressed(key ebiten.Key) bool
+	// Chars is the text typed this frame
+	Chars() []rune
+	// AnyJustPressed reports whether any key at all went down this frame
+	AnyJustPressed() bool
+	// Click is where the left mouse button went down this frame, if it did
+	Click() (image.Point, bool)
+	// Cursor is where the mouse is now
+	Cursor() image.Point
+}
+
+// noInput reports nothing pressed, for letting Update run while keys go
+// elsewhere
+type noInput struct{}
+
+func (noInput) Tick()                       {}
+func (noInput) JustPressed(ebiten.Key) bool { return false }
+func (noInput) Pressed(ebiten.Key) bool     { return false }
+func (noInput) Chars() []rune               { return nil }
+func (noInput) AnyJustPressed() bool        { return false }
+func (noInput) Click() (image.Point, bool)  { return image.Point{}, false }
+func (noInput) Cursor() image.Point         { return image.Pt(-1, -1) }
+
+// ebitenInput reads the real keyboard
+type ebitenInput struct{}
+
+func (ebitenInput) Tick()                           {}
+func (ebitenInput) JustPressed(key ebiten.Key) bool { return inpututil.IsKeyJustPressed(key) }
+func (ebitenInput) Pressed(key ebiten.Key) bool     { return ebiten.IsKeyPressed(key) }
+func (ebitenInput) Chars() []rune                   { return ebiten.AppendInputChars(nil) }
+func (ebitenInput) AnyJustPressed() bool            { return len(inpututil.AppendJustPressedKeys(nil)) > 0 }
+
+func (ebitenInput) Click() (image.Point, bool) {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return image.Point{}, false
+	}
+	return image.Pt(ebiten.CursorPosition()), true
+}
+
+func (ebitenInput) Cursor() image.Point { return image.Pt(ebiten.CursorPosition()) }
+
+const (
+	repeatDelay    = 400 * time.Millisecond // hold time before a key starts repeating
+	repeatInterval = 50 * time.Millisecond
+	menuInterval   = 180 * time.Millisecond // slower, so a held arrow doesn't blur through the modes
+)
+
+// keyRepeat tracks a held key so it acts once when pressed and then repeats
+// at a steady rate, instead of once per frame
+type keyRepeat struct {
+	interval   time.Duration // between repeats, zero means repeatInterval
+	heldSince  time.Time
+	lastRepeat time.Time
+}
+
+// fire reports whether the key should act this frame: once on the initial
+// press, then every interval once it has been held for repeatDelay
+func (r *keyRepeat) fire(justPressed, pressed bool, now time.Time) bool {
+	if justPressed {
+		r.heldSince = now
+		r.lastRepeat = now
+		return true
+	}
+	if !pressed || r.heldSince.IsZero() {
+		r.heldSince = time.Time{}
+		return false
+	}
+	interval := r.interval
+	if interval == 0 {
+		interval = repeatInterval
+	}
+	if now.Sub(r.heldSince) < repeatDelay || now.Sub(r.lastRepeat) < interval {
+		return false
+	}
+	r.lastRepeat = now
+	return true
+}
+
+// repeating fires r for whatever key action is bound to, on the game clock
+func (g *Game) repeating(r *keyRepeat, action Action) bool {
+	return r.fire(g.justPressed(action), g.pressed(action), g.now())
+}
+
+// Editing keys in the text prompts. These aren't in the keymap: with an
+// action rebound to a letter, typing that letter mustn't also move the caret.
+const (
+	editLeft      = ebiten.KeyLeft
+	editRight     = ebiten.KeyRight
+	editUp        = ebiten.KeyUp
+	editDown      = ebiten.KeyDown
+	editHome      = ebiten.KeyHome
+	editEnd       = ebiten.KeyEnd
+	editBackspace = ebiten.KeyBackspace
+)
+
+// repeatingKey is repeating for a fixed key
+func (g *Game) repeatingKey(r *keyRepeat, key ebiten.Key) bool {
+	return r.fire(g.input.JustPressed(key), g.input.Pressed(key), g.now())
+}
+
+// insertAt puts s into buf at rune index caret and returns the new buffer and
+// the caret moved past the inserted text
+func insertAt(buf string, caret int, s string) (string, int) {
+	runes := []rune(buf)
+	ins := []rune(s)
+	out := append(append(append([]rune{}, runes[:caret]...), ins...), runes[caret:]...)
+	return string(out), caret + len(ins)
+}
+
+// defaultMaxInput is how many runes the input line takes before it stops,
+// plenty for any real path without letting a huge paste through
+const defaultMaxInput = 4096
+
+// fitInput cuts s down so buf plus s stays within limit runes, and reports
+// whether anything had to go. A limit of 0 or less means no limit.
+func fitInput(buf, s string, limit int) (string, bool) {
+	if limit <= 0 {
+		return s, false
+	}
+	room := max(0, limit-utf8.RuneCountInString(buf))
+	ins := []rune(s)
+	if len(ins) <= room {
+		return s, false
+	}
+	return string(ins[:room]), true
+}
+
+// zeroWidthJoiner glues two characters into one, like the parts of a family emoji
+const zeroWidthJoiner = '\u200d'
+
+// The emoji skin tones, which change the emoji before them
+const (
+	firstSkinTone = '\U0001F3FB'
+	lastSkinTone  = '\U0001F3FF'
+)
+
+// isCombining reports whether r attaches to the rune before it instead of
+// standing on its own: accents typed as dead keys, variation selectors, skin
+// tones and joiners
+func isCombining(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) ||
+		r == zeroWidthJoiner || (r >= firstSkinTone && r <= lastSkinTone)
+}
+
+// charStart is the rune index where the character ending at caret begins, so
+// an e with its accent, or a joined emoji, moves and deletes as one
+func charStart(runes []rune, caret int) int {
+	i := caret - 1
+	for i > 0 {
+		switch {
+		case isCombining(runes[i]):
+			i--
+		case runes[i-1] == zeroWidthJoiner && i >= 2:
+			i -= 2
+		default:
+			return i
+		}
+	}
+	return max(0, i)
+}
+
+// charEnd is the rune index just past the character starting at caret
+func charEnd(runes []rune, caret int) int {
+	i := caret + 1
+	for i < len(runes) && (isCombining(runes[i]) || runes[i-1] == zeroWidthJoiner) {
+		i++
+	}
+	return min(i, len(runes))
+}
+
+// caretLeft and caretRight step the caret over one whole character
+func caretLeft(buf string, caret int) int {
+	if caret == 0 {
+		return 0
+	}
+	return charStart([]rune(buf), caret)
+}
+
+func caretRight(buf string, caret int) int {
+	runes := []rune(buf)
+	if caret >= len(runes) {
+		return len(runes)
+	}
+	return charEnd(runes, caret)
+}
+
+// typedChars drops whatever a keyboard layout sends through as text that
+// isn't, control characters like DEL and anything that wasn't valid UTF-8
+func typedChars(chars []rune) []rune {
+	return slices.DeleteFunc(chars, func(r rune) bool { return unicode.IsControl(r) || r == utf8.RuneError })
+}
+
+// deleteBefore removes the character before caret, like backspace. That's a
+// whole rune, along with anything combined into it.
+func deleteBefore(buf string, caret int) (string, int) {
+	if caret == 0 {
+		return buf, 0
+	}
+	runes := []rune(buf)
+	start := charStart(runes, caret)
+	return string(append(runes[:start], runes[caret:]...)), start
+}
+
+// deleteWordBefore is Ctrl+Backspace: it removes any separators right before
+// caret and then everything back to the previous one, so /usr/local/bin goes
+// to /usr/local/ and then /usr/. Slashes and whitespace count as separators.
+func deleteWordBefore(buf string, caret int) (string, int) {
+	runes := []rune(buf)
+	isSep := func(r rune) bool { return r == '/' || r == '\\' || unicode.IsSpace(r) }
+
+	start := caret
+	for start > 0 && isSep(runes[start-1]) {
+		start--
+	}
+	for start > 0 && !isSep(runes[start-1]) {
+		start--
+	}
+	return string(append(runes[:start], runes[caret:]...)), start
+}
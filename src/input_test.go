@@ -0,0 +1,397 @@
+package main
+
+import (
+	"image"
+	"slices"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// fakeInput is an InputSource the tests script by hand. Whatever is staged
+// shows up on the next Tick, so it's seen by exactly one Update.
+type fakeInput struct {
+	held        map[ebiten.Key]bool
+	just        map[ebiten.Key]bool
+	staged      map[ebiten.Key]bool
+	chars       []rune
+	stagedChars []rune
+	click       *image.Point
+	stagedClick *image.Point
+	cursor      image.Point
+}
+
+func newFakeInput() *fakeInput {
+	return &fakeInput{held: map[ebiten.Key]bool{}, just: map[ebiten.Key]bool{}, staged: map[ebiten.Key]bool{}, cursor: image.Pt(-1, -1)}
+}
+
+func (f *fakeInput) Tick() {
+	f.just, f.staged = f.staged, map[ebiten.Key]bool{}
+	f.chars, f.stagedChars = f.stagedChars, nil
+	f.click, f.stagedClick = f.stagedClick, nil
+}
+
+func (f *fakeInput) JustPressed(key ebiten.Key) bool { return f.just[key] }
+func (f *fakeInput) Pressed(key ebiten.Key) bool     { return f.held[key] }
+func (f *fakeInput) Chars() []rune                   { return f.chars }
+func (f *fakeInput) AnyJustPressed() bool            { return len(f.just) > 0 }
+func (f *fakeInput) Cursor() image.Point             { return f.cursor }
+
+func (f *fakeInput) Click() (image.Point, bool) {
+	if f.click == nil {
+		return image.Point{}, false
+	}
+	return *f.click, true
+}
+
+// press puts key down from the next Update on, until release
+func (f *fakeInput) press(key ebiten.Key) {
+	f.staged[key] = true
+	f.held[key] = true
+}
+
+func (f *fakeInput) release(key ebiten.Key) {
+	delete(f.held, key)
+}
+
+// clickAt moves the mouse to pt and clicks there on the next Update
+func (f *fakeInput) clickAt(pt image.Point) {
+	f.cursor = pt
+	f.stagedClick = &pt
+}
+
+// testGame is a game at the menu with a fresh save, the tutorial already
+// seen and the config directory pointed somewhere disposable
+func testGame(t *testing.T) (*Game, *fakeInput) {
+	t.Helper()
+	t.Setenv(configDirEnv, t.TempDir())
+	save := newSaveData()
+	save.TutorialSeen = true
+	in := newFakeInput()
+	g := newGame(in, save)
+	g.screenWidth, g.screenHeight = 1920, 1080
+	return g, in
+}
+
+// update runs n Updates, failing the test on an error
+func update(t *testing.T, g *Game, n int) {
+	t.Helper()
+	for range n {
+		if err := g.Update(); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+}
+
+// tap presses and releases key over one Update
+func tap(t *testing.T, g *Game, in *fakeInput, key ebiten.Key) {
+	t.Helper()
+	in.press(key)
+	update(t, g, 1)
+	in.release(key)
+}
+
+// typeText types s in one Update
+func typeText(t *testing.T, g *Game, in *fakeInput, s string) {
+	t.Helper()
+	in.stagedChars = []rune(s)
+	update(t, g, 1)
+}
+
+func TestKeyRepeatSchedule(t *testing.T) {
+	var r keyRepeat
+	start := gameEpoch
+	fired := 0
+	// held for a second, polled every 10ms
+	for ms := 0; ms <= 1000; ms += 10 {
+		if r.fire(ms == 0, true, start.Add(time.Duration(ms)*time.Millisecond)) {
+			fired++
+		}
+	}
+	// the press, then repeats at 400, 450, ... 1000ms
+	if want := 1 + 13; fired != want {
+		t.Fatalf("fired %d times in a second, want %d", fired, want)
+	}
+	if r.fire(false, false, start.Add(2*time.Second)) {
+		t.Fatal("fired after release")
+	}
+}
+
+func TestHeldBackspace(t *testing.T) {
+	g, in := testGame(t)
+	g.startPrompt()
+	g.setInput("abcdefghijklmnopqrstuvwxyz")
+
+	// A second held at 60 TPS is ticks 0 to 59. The press deletes on tick 0,
+	// the 400ms delay is up on tick 25 and the clock rounds the 50ms
+	// interval up to 4 ticks, so repeats land on 25, 29, ... 57.
+	in.press(ebiten.KeyBackspace)
+	update(t, g, 25)
+	if g.inputBuffer != "abcdefghijklmnopqrstuvwxy" {
+		t.Fatalf("deleted ahead of the repeat delay, %q left", g.inputBuffer)
+	}
+	update(t, g, 35)
+	in.release(ebiten.KeyBackspace)
+	update(t, g, 10)
+	if want := "abcdefghijklmnop"; g.inputBuffer != want {
+		t.Fatalf("holding for a second left %q, want %q", g.inputBuffer, want)
+	}
+}
+
+// openPrompt gets from the menu to the SAFE directory prompt
+func openPrompt(t *testing.T, g *Game, in *fakeInput) {
+	t.Helper()
+	tap(t, g, in, ebiten.KeyEnter)
+	if !g.inputActive {
+		t.Fatal("enter on SAFE didn't open the directory prompt")
+	}
+}
+
+func TestCaretMovesAndDeletesMidString(t *testing.T) {
+	g, in := testGame(t)
+	openPrompt(t, g, in)
+	typeText(t, g, in, "abcdef")
+	if g.inputCaret != 6 {
+		t.Fatalf("caret at %d after typing, want the end", g.inputCaret)
+	}
+
+	steps := []struct {
+		key   ebiten.Key
+		input string
+		caret int
+	}{
+		{ebiten.KeyLeft, "abcdef", 5},
+		{ebiten.KeyLeft, "abcdef", 4},
+		{ebiten.KeyBackspace, "abcef", 3},
+		{ebiten.KeyRight, "abcef", 4},
+		{ebiten.KeyHome, "abcef", 0},
+		{ebiten.KeyBackspace, "abcef", 0}, // nothing before the caret
+		{ebiten.KeyLeft, "abcef", 0},
+		{ebiten.KeyEnd, "abcef", 5},
+		{ebiten.KeyRight, "abcef", 5},
+	}
+	for i, step := range steps {
+		tap(t, g, in, step.key)
+		update(t, g, 1)
+		if g.inputBuffer != step.input || g.inputCaret != step.caret {
+			t.Fatalf("step %d: %q caret %d, want %q caret %d", i, g.inputBuffer, g.inputCaret, step.input, step.caret)
+		}
+	}
+
+	g.inputCaret = 2
+	typeText(t, g, in, "XY")
+	if g.inputBuffer != "abXYcef" || g.inputCaret != 4 {
+		t.Errorf("typing mid-string gave %q caret %d, want \"abXYcef\" caret 4", g.inputBuffer, g.inputCaret)
+	}
+}
+
+func TestDeleteWordBefore(t *testing.T) {
+	tests := []struct {
+		buf   string
+		caret int
+		want  string
+		at    int
+	}{
+		{"/usr/local/bin", 14, "/usr/local/", 11},
+		{"/usr/local/", 11, "/usr/", 5},
+		{"/usr/", 5, "/", 1},
+		{"/", 1, "", 0},
+		{"", 0, "", 0},
+		{"/usr/local/bin", 10, "/usr//bin", 5}, // only the word before the caret
+		{"/usr/local/bin", 0, "/usr/local/bin", 0},
+		{"rm a.txt b.txt", 14, "rm a.txt ", 9},
+		{"rm a.txt   ", 11, "rm ", 3},
+		{`C:\Users\me`, 11, `C:\Users\`, 9},
+	}
+	for _, tt := range tests {
+		got, at := deleteWordBefore(tt.buf, tt.caret)
+		if got != tt.want || at != tt.at {
+			t.Errorf("deleteWordBefore(%q, %d) = %q, %d, want %q, %d", tt.buf, tt.caret, got, at, tt.want, tt.at)
+		}
+	}
+}
+
+func TestMenuToScanWithFakeInput(t *testing.T) {
+	g, in := testGame(t)
+	root := writeTree(t, map[string]string{"a.txt": "a"})
+	openPrompt(t, g, in)
+	typeText(t, g, in, root)
+	tap(t, g, in, ebiten.KeyEnter)
+	defer g.cancelScan()
+	if g.state != StateEngaging {
+		t.Fatalf("enter on %q went to %v, want engaging", root, g.state)
+	}
+
+	for i := 0; i < 60*10 && g.state == StateEngaging; i++ {
+		update(t, g, 1)
+	}
+	if g.state != StateFSInit && g.state != StatePlaying {
+		t.Fatalf("engaging ended in %v, want the scan", g.state)
+	}
+}
+
+func TestFitInput(t *testing.T) {
+	for _, c := range []struct {
+		buf, s string
+		limit  int
+		want   string
+		cut    bool
+	}{
+		{"abc", "de", 5, "de", false},
+		{"abc", "def", 5, "de", true},
+		{"abcde", "f", 5, "", true},
+		{"abcdef", "g", 5, "", true},  // already over, nothing more goes in
+		{"", "héllo", 3, "hél", true}, // runes, not bytes
+		{"日本", "語です", 3, "語", true},
+		{"abc", "anything at all", 0, "anything at all", false},
+		{"abc", "x", -1, "x", false},
+	} {
+		got, cut := fitInput(c.buf, c.s, c.limit)
+		if got != c.want || cut != c.cut {
+			t.Errorf("fitInput(%q, %q, %d) = %q, %v, want %q, %v", c.buf, c.s, c.limit, got, cut, c.want, c.cut)
+		}
+	}
+}
+
+func TestInputStopsAtLimit(t *testing.T) {
+	g, in := testGame(t)
+	g.maxInput = 8
+	openPrompt(t, g, in)
+	typeText(t, g, in, "/tmp/abcdefgh")
+	if g.inputBuffer != "/tmp/abc" {
+		t.Errorf("typing past the limit left %q", g.inputBuffer)
+	}
+	if !slices.Contains(toastMsgs(g), tr("toast.input_full")) {
+		t.Error("no toast when the input hit its limit")
+	}
+
+	g.clipboard = &fakeClipboard{text: "more", ok: true}
+	in.press(ebiten.KeyControl)
+	tap(t, g, in, ebiten.KeyV)
+	in.release(ebiten.KeyControl)
+	if g.inputBuffer != "/tmp/abc" {
+		t.Errorf("pasting into a full input left %q", g.inputBuffer)
+	}
+}
+
+func TestDeleteBefore(t *testing.T) {
+	for _, c := range []struct {
+		buf   string
+		caret int
+		want  string
+		at    int
+	}{
+		{"abc", 3, "ab", 2},
+		{"abc", 0, "abc", 0},
+		{"/tmp/café", 9, "/tmp/caf", 8},        // é is two bytes, one rune
+		{"/tmp/cafe\u0301", 10, "/tmp/caf", 8}, // e with a combining accent
+		{"日本語", 2, "日語", 1},
+		{"go 👍🏽", 5, "go ", 3},  // emoji with a skin tone
+		{"x👨‍👩‍👧y", 6, "xy", 1}, // a family is one character
+	} {
+		got, at := deleteBefore(c.buf, c.caret)
+		if got != c.want || at != c.at {
+			t.Errorf("deleteBefore(%q, %d) = %q, %d, want %q, %d", c.buf, c.caret, got, at, c.want, c.at)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("deleteBefore(%q, %d) left invalid UTF-8", c.buf, c.caret)
+		}
+	}
+}
+
+func TestCaretStepsOverCharacters(t *testing.T) {
+	buf := "aé👨‍👩z"
+	// a, e+accent, the joined pair, z
+	stops := []int{0, 1, 3, 6, 7}
+	caret := len([]rune(buf))
+	for i := len(stops) - 1; i > 0; i-- {
+		if caret != stops[i] {
+			t.Fatalf("caret stopped at %d, want %d", caret, stops[i])
+		}
+		caret = caretLeft(buf, caret)
+	}
+	if caret != 0 || caretLeft(buf, 0) != 0 {
+		t.Fatalf("caretLeft didn't end at the start, got %d", caret)
+	}
+	for _, want := range stops[1:] {
+		if caret = caretRight(buf, caret); caret != want {
+			t.Errorf("caretRight stopped at %d, want %d", caret, want)
+		}
+	}
+	if caretRight(buf, caret) != caret {
+		t.Error("caretRight went past the end")
+	}
+}
+
+func TestBackspaceMultiByteInput(t *testing.T) {
+	g, in := testGame(t)
+	openPrompt(t, g, in)
+	in.stagedChars = []rune{'/', 'ü', 'b', 'e', '\u0301', 'r', 0x7f}
+	update(t, g, 1)
+	if g.inputBuffer != "/übe\u0301r" {
+		t.Fatalf("typed %q, want the composed input without DEL", g.inputBuffer)
+	}
+	for _, want := range []string{"/übe\u0301", "/üb", "/ü", "/", ""} {
+		tap(t, g, in, ebiten.KeyBackspace)
+		update(t, g, 30) // let the held-key repeat timer settle
+		if g.inputBuffer != want {
+			t.Fatalf("backspace left %q, want %q", g.inputBuffer, want)
+		}
+	}
+}
+
+// typeKey types a letter the way a keyboard does, the key going down along
+// with its character
+func typeKey(t *testing.T, g *Game, in *fakeInput, key ebiten.Key, r rune) {
+	t.Helper()
+	in.press(key)
+	in.stagedChars = []rune{r}
+	update(t, g, 1)
+	in.release(key)
+}
+
+func TestReboundLettersStillType(t *testing.T) {
+	g, in := testGame(t)
+	g.keymap[ActionMoveLeft] = ebiten.KeyA
+	g.keymap[ActionMoveRight] = ebiten.KeyD
+	openPrompt(t, g, in)
+	typeText(t, g, in, "/tmp/x")
+	typeKey(t, g, in, ebiten.KeyA, 'a')
+	typeKey(t, g, in, ebiten.KeyD, 'd')
+	if g.inputBuffer != "/tmp/xad" || g.inputCaret != 8 {
+		t.Fatalf("typing rebound letters gave %q with the caret at %d", g.inputBuffer, g.inputCaret)
+	}
+
+	// The arrows, Home and End still edit, whatever the keymap says
+	tap(t, g, in, ebiten.KeyLeft)
+	if g.inputCaret != 7 {
+		t.Errorf("left arrow put the caret at %d", g.inputCaret)
+	}
+	tap(t, g, in, ebiten.KeyHome)
+	if g.inputCaret != 0 {
+		t.Errorf("Home put the caret at %d", g.inputCaret)
+	}
+	tap(t, g, in, ebiten.KeyEnd)
+	if g.inputCaret != 8 {
+		t.Errorf("End put the caret at %d", g.inputCaret)
+	}
+}
+
+func TestReboundLettersDontRecallHistory(t *testing.T) {
+	g, in := testGame(t)
+	g.keymap[ActionMoveUp] = ebiten.KeyW
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	tap(t, g, in, ebiten.KeyTab)
+	runConsole(t, g, in, "help")
+	typeKey(t, g, in, ebiten.KeyW, 'w')
+	if g.inputBuffer != "w" {
+		t.Fatalf("typing a rebound w gave %q", g.inputBuffer)
+	}
+	g.setInput("")
+	tap(t, g, in, ebiten.KeyUp)
+	if g.inputBuffer != "help" {
+		t.Errorf("up arrow recalled %q, want the last command", g.inputBuffer)
+	}
+}
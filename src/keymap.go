@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Action is a logical input the game responds to, independent of which key
+// triggers it
+type Action string
+
+const (
+	ActionMoveLeft   Action = "MoveLeft"
+	ActionMoveRight  Action = "MoveRight"
+	ActionMoveUp     Action = "MoveUp"
+	ActionMoveDown   Action = "MoveDown"
+	ActionPageUp     Action = "PageUp"
+	ActionPageDown   Action = "PageDown"
+	ActionConfirm    Action = "Confirm"
+	ActionDelete     Action = "Delete"
+	ActionPause      Action = "Pause"
+	ActionCancel     Action = "Cancel"
+	ActionSecure     Action = "Secure"
+	ActionConsole    Action = "Console"
+	ActionSearch     Action = "Search"
+	ActionYank       Action = "Yank"
+	ActionSort       Action = "Sort"
+	ActionMute       Action = "Mute"
+	ActionSettings   Action = "Settings"
+	ActionReboot     Action = "Reboot"
+	ActionResume     Action = "Resume"
+	ActionQuit       Action = "Quit"
+	ActionAbandon    Action = "Abandon"
+	ActionFullscreen Action = "Fullscreen"
+	ActionDebug      Action = "Debug"
+	ActionBoss       Action = "Boss"
+	ActionHelp       Action = "Help"
+)
+
+// Keymap binds each action to a key
+type Keymap map[Action]ebiten.Key
+
+func defaultKeymap() Keymap {
+	return Keymap{
+		ActionMoveLeft:   ebiten.KeyLeft,
+		ActionMoveRight:  ebiten.KeyRight,
+		ActionMoveUp:     ebiten.KeyUp,
+		ActionMoveDown:   ebiten.KeyDown,
+		ActionPageUp:     ebiten.KeyPageUp,
+		ActionPageDown:   ebiten.KeyPageDown,
+		ActionConfirm:    ebiten.KeyEnter,
+		ActionDelete:     ebiten.KeyBackspace,
+		ActionPause:      ebiten.KeyEscape,
+		ActionCancel:     ebiten.KeyEscape,
+		ActionSecure:     ebiten.KeySpace,
+		ActionConsole:    ebiten.KeyTab,
+		ActionSearch:     ebiten.KeySlash,
+		ActionYank:       ebiten.KeyY,
+		ActionSort:       ebiten.KeyO,
+		ActionMute:       ebiten.KeyM,
+		ActionSettings:   ebiten.KeyS,
+		ActionReboot:     ebiten.KeyB,
+		ActionResume:     ebiten.KeyR,
+		ActionQuit:       ebiten.KeyQ,
+		ActionAbandon:    ebiten.KeyQ,
+		ActionFullscreen: ebiten.KeyF11,
+		ActionDebug:      ebiten.KeyF3,
+		ActionBoss:       ebiten.KeyBackquote,
+		ActionHelp:       ebiten.KeyF1,
+	}
+}
+
+// loadKeymap reads key overrides from path on top of the defaults, e.g.
+// {"MoveLeft": "A", "MoveRight": "D"}. A missing file just means defaults.
+func loadKeymap(path string) (Keymap, error) {
+	km := defaultKeymap()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return km, nil
+	}
+	if err != nil {
+		return km, err
+	}
+
+	var overrides map[Action]ebiten.Key
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return km, err
+	}
+	for action, key := range overrides {
+		if _, ok := km[action]; !ok {
+			return km, fmt.Errorf("unknown action %q", action)
+		}
+		km[action] = key
+	}
+	return km, nil
+}
+
+// justPressed reports whether the key bound to a was pressed this frame
+func (g *Game) justPressed(a Action) bool {
+	return g.input.JustPressed(g.keymap[a])
+}
+
+// pressed reports whether the key bound to a is held down
+func (g *Game) pressed(a Action) bool {
+	return g.input.Pressed(g.keymap[a])
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestLoadKeymap(t *testing.T) {
+	dir := t.TempDir()
+	km, err := loadKeymap(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+	if km[ActionMoveLeft] != ebiten.KeyLeft {
+		t.Errorf("missing file: MoveLeft bound to %v, want the default", km[ActionMoveLeft])
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"Jump": "J"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadKeymap(bad); err == nil {
+		t.Error("unknown action loaded without an error")
+	}
+}
+
+func TestOverriddenKeysSwitchModes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keybindings.json")
+	if err := os.WriteFile(path, []byte(`{"MoveLeft": "A", "MoveRight": "D"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	km, err := loadKeymap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, in := testGame(t)
+	g.keymap = km
+
+	tap(t, g, in, ebiten.KeyRight)
+	if g.currentMode != ModeSafe {
+		t.Fatalf("the unbound right arrow still switched to %v", modeNames[g.currentMode])
+	}
+	update(t, g, 5)
+	tap(t, g, in, ebiten.KeyD)
+	if g.currentMode != ModeDestruction {
+		t.Fatalf("D: mode %v, want %v", modeNames[g.currentMode], modeNames[ModeDestruction])
+	}
+	update(t, g, 5)
+	tap(t, g, in, ebiten.KeyA)
+	if g.currentMode != ModeSafe {
+		t.Fatalf("A: mode %v, want %v", modeNames[g.currentMode], modeNames[ModeSafe])
+	}
+}
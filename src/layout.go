@@ -0,0 +1,149 @@
+package main
+
+import (
+	"image/color"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Everything on screen is positioned through these helpers rather than fixed
+// pixel coordinates, so the UI follows the window when it gets resized.
+
+// marginX is the left edge of the terminal text, about 1% of the width
+func (g *Game) marginX() int {
+	return max(10, g.screenWidth/96)
+}
+
+// marginY is the gap above the first line of text
+func (g *Game) marginY() int {
+	return max(10, g.screenHeight/54)
+}
+
+// lineHeight is the distance between text rows, with a little breathing room
+// on top of the font's own line height
+func lineHeight() int {
+	return mplusNormalFont.Metrics().Height.Ceil() * 4 / 3
+}
+
+// lineY is the baseline y coordinate of the given text row
+func (g *Game) lineY(row int) int {
+	return g.marginY() + mplusNormalFont.Metrics().Ascent.Ceil() + row*lineHeight()
+}
+
+// textWidth is how wide a line of text may be before it has to wrap
+func (g *Game) textWidth() int {
+	return g.screenWidth - 2*g.marginX()
+}
+
+// rowsFrom is how many text rows fit between the given row and the bottom of the screen
+func (g *Game) rowsFrom(row int) int {
+	return max(1, (g.screenHeight-g.marginY()-g.lineY(row))/lineHeight()+1)
+}
+
+// wrapText splits s into lines no wider than maxWidth when drawn with face.
+// Lines break after a space or path separator where possible, otherwise
+// mid-word, so a long path with no spaces still wraps.
+func wrapText(s string, face font.Face, maxWidth int) []string {
+	limit := fixed.I(maxWidth)
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > 0 {
+		// Grow the line until the next rune would overflow
+		end := 1
+		for end < len(runes) && font.MeasureString(face, string(runes[:end+1])) <= limit {
+			end++
+		}
+		if end < len(runes) {
+			for i := end; i > 0; i-- {
+				if runes[i-1] == ' ' || runes[i-1] == '/' || runes[i-1] == '\\' {
+					end = i
+					break
+				}
+			}
+		}
+		lines = append(lines, string(runes[:end]))
+		runes = runes[end:]
+	}
+	return lines
+}
+
+// drawWrappedText draws s starting with its first baseline at y, wrapping at
+// maxWidth, and returns how many lines it took so callers can move down
+func drawWrappedText(screen *ebiten.Image, s string, face font.Face, x, y, maxWidth int, clr color.Color) int {
+	lines := wrapText(s, face, maxWidth)
+	for i, line := range lines {
+		text.Draw(screen, line, face, x, y+i*lineHeight(), clr)
+	}
+	return len(lines)
+}
+
+// drawBlockCursor draws a solid character cell on the text row whose baseline
+// is y, the font has no block glyph for it
+func (g *Game) drawBlockCursor(screen *ebiten.Image, x, y int, clr color.Color) {
+	ascent := mplusNormalFont.Metrics().Ascent.Ceil()
+	w := font.MeasureString(mplusNormalFont, "_").Ceil()
+	vector.FillRect(screen, float32(x), float32(y-ascent), float32(w), float32(ascent), clr, false)
+}
+
+// fitText chops s until it's no wider than width, keeping at least one rune
+func fitText(s string, width int) string {
+	r := []rune(s)
+	for font.MeasureString(mplusNormalFont, string(r)).Ceil() > width && len(r) > 1 {
+		r = r[:len(r)-1]
+	}
+	return string(r)
+}
+
+// drawBold fakes a heavier weight for emphasis, the font only has the one,
+// by drawing s a second time a pixel to the right
+func drawBold(screen *ebiten.Image, s string, face font.Face, x, y int, clr color.Color) {
+	text.Draw(screen, s, face, x, y, clr)
+	text.Draw(screen, s, face, x+1, y, clr)
+}
+
+// drawWrappedBold is drawWrappedText in drawBold
+func drawWrappedBold(screen *ebiten.Image, s string, face font.Face, x, y, maxWidth int, clr color.Color) int {
+	lines := wrapText(s, face, maxWidth)
+	for i, line := range lines {
+		drawBold(screen, line, face, x, y+i*lineHeight(), clr)
+	}
+	return len(lines)
+}
+
+// promptLines is prefix and the input line as drawPrompt lays them out
+func (g *Game) promptLines(prefix string, maxWidth int) []string {
+	if maxWidth > 0 {
+		return wrapText(prefix+g.inputBuffer, mplusNormalFont, maxWidth)
+	}
+	return []string{prefix + g.inputBuffer}
+}
+
+// drawPrompt draws prefix followed by the input line, wrapped at maxWidth or
+// kept on one line when maxWidth is 0, and returns how many lines it took.
+// The blinking caret is an underscore under the character it sits before.
+func (g *Game) drawPrompt(screen *ebiten.Image, prefix string, x, y, maxWidth int, clr color.Color) int {
+	lines := g.promptLines(prefix, maxWidth)
+	for i, line := range lines {
+		text.Draw(screen, line, mplusNormalFont, x, y+i*lineHeight(), clr)
+	}
+	if !g.cursorVisible() {
+		return len(lines)
+	}
+
+	// Walk down the wrapped lines to the one holding the caret
+	pos, row := utf8.RuneCountInString(prefix)+g.inputCaret, 0
+	for row < len(lines)-1 && pos > utf8.RuneCountInString(lines[row]) {
+		pos -= utf8.RuneCountInString(lines[row])
+		row++
+	}
+	before := []rune(lines[row])
+	before = before[:min(pos, len(before))]
+	caretX := x + font.MeasureString(mplusNormalFont, string(before)).Ceil()
+	text.Draw(screen, "_", mplusNormalFont, caretX, y+row*lineHeight(), clr)
+	return len(lines)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+func TestWrapText(t *testing.T) {
+	// VT323 is monospaced, so widths are a whole number of cells
+	cell := font.MeasureString(mplusNormalFont, "a").Ceil()
+	tests := []struct {
+		s     string
+		cells int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"hello world", 8, []string{"hello ", "world"}},
+		{"/usr/local/bin", 8, []string{"/usr/", "local/", "bin"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}}, // nowhere to break, so mid-word
+		{"", 10, nil},
+	}
+	for _, tt := range tests {
+		got := wrapText(tt.s, mplusNormalFont, tt.cells*cell)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %d cells) = %q, want %q", tt.s, tt.cells, got, tt.want)
+		}
+		for _, line := range got {
+			if w := font.MeasureString(mplusNormalFont, line).Ceil(); w > tt.cells*cell {
+				t.Errorf("line %q is %dpx, over the %dpx limit", line, w, tt.cells*cell)
+			}
+		}
+	}
+}
+
+func TestDrawBold(t *testing.T) {
+	// Pixels can't be read back before the game loop runs, so this only
+	// checks that drawing goes through, wrapped or not
+	screen := ebiten.NewImage(400, 200)
+	drawBold(screen, "WARNING", mplusNormalFont, 10, 40, color.White)
+	if rows := drawWrappedBold(screen, "WARNING: MODE ENGAGED: DANGER", mplusNormalFont, 10, 80, 200, color.White); rows < 2 {
+		t.Errorf("wrapped bold took %d rows in 200px, want it wrapped", rows)
+	}
+}
+
+func TestWarningBootLineIsBold(t *testing.T) {
+	g, _ := testGame(t)
+	g.startBoot()
+	if warning := modeEngagedLine(ModeDanger).Text; !g.bootLineBold(0, warning) {
+		t.Errorf("%q isn't drawn bold", warning)
+	}
+	if safe := modeEngagedLine(ModeSafe).Text; g.bootLineBold(0, safe) {
+		t.Errorf("%q is drawn bold", safe)
+	}
+	if g.bootLineBold(1, bootSequence[1].Text) {
+		t.Errorf("plain boot line %q is drawn bold", bootSequence[1].Text)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+)
+
+// On-screen text is looked up by id in a per-locale catalog. English is
+// always loaded underneath, so a locale only has to list what it translates.
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+const defaultLocale = "en"
+
+var (
+	fallbackMessages = mustLoadCatalog(defaultLocale)
+	messages         = fallbackMessages
+)
+
+func loadCatalog(locale string) (map[string]string, error) {
+	data, err := localeFiles.ReadFile("locales/" + locale + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var catalog map[string]string
+	err = json.Unmarshal(data, &catalog)
+	return catalog, err
+}
+
+func mustLoadCatalog(locale string) map[string]string {
+	catalog, err := loadCatalog(locale)
+	if err != nil {
+		panic("bad embedded locale " + locale + ": " + err.Error())
+	}
+	return catalog
+}
+
+// envLocale picks the locale from TERMIWAR_LANG, then LANG, so es_ES.UTF-8
+// becomes es
+func envLocale() string {
+	lang := os.Getenv("TERMIWAR_LANG")
+	if lang == "" {
+		lang = os.Getenv("LANG")
+	}
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	return strings.ToLower(lang)
+}
+
+// setLocale switches the catalog. Unknown locales stay on English, and any
+// message the font can't draw is dropped so it falls back to English instead
+// of rendering as blanks.
+func setLocale(locale string) {
+	messages = fallbackMessages
+	if locale == "" || locale == defaultLocale {
+		return
+	}
+	catalog, err := loadCatalog(locale)
+	if err != nil {
+		log.Printf("no %q locale, using English", locale)
+		return
+	}
+	for id, msg := range catalog {
+		if !fontCovers(msg) {
+			delete(catalog, id)
+		}
+	}
+	messages = catalog
+}
+
+// fontCovers reports whether the main face has a glyph for every rune in s
+func fontCovers(s string) bool {
+	for _, r := range s {
+		if _, ok := mplusNormalFont.GlyphAdvance(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tr looks up a message, falling back to English and then to the id itself
+func tr(id string) string {
+	if msg, ok := messages[id]; ok {
+		return msg
+	}
+	if msg, ok := fallbackMessages[id]; ok {
+		return msg
+	}
+	return id
+}
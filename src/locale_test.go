@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// localesEmbedded is every locale shipped, by name
+func localesEmbedded(t *testing.T) []string {
+	t.Helper()
+	files, err := fs.Glob(localeFiles, "locales/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(path.Base(f), ".json"))
+	}
+	return names
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestLocalesComplete(t *testing.T) {
+	for _, locale := range localesEmbedded(t) {
+		catalog, err := loadCatalog(locale)
+		if err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+		for id, en := range fallbackMessages {
+			msg, ok := catalog[id]
+			if !ok {
+				t.Errorf("%s is missing %q", locale, id)
+				continue
+			}
+			// A translation takes the same arguments in the same order
+			if got, want := formatVerb.FindAllString(msg, -1), formatVerb.FindAllString(en, -1); !slices.Equal(got, want) {
+				t.Errorf("%s %q formats with %q, English with %q", locale, id, got, want)
+			}
+		}
+		for id := range catalog {
+			if _, ok := fallbackMessages[id]; !ok {
+				t.Errorf("%s has %q, which English doesn't", locale, id)
+			}
+		}
+	}
+}
+
+func TestMissingMessageFallsBackToEnglish(t *testing.T) {
+	t.Cleanup(func() { setLocale(defaultLocale) })
+	setLocale("es")
+	if tr("won.title") != "VICTORIA" {
+		t.Fatalf("es gave %q for won.title", tr("won.title"))
+	}
+
+	delete(messages, "lost.title")
+	if got := tr("lost.title"); got != fallbackMessages["lost.title"] {
+		t.Errorf("a message missing from es gave %q, want the English", got)
+	}
+	if got := tr("no.such.id"); got != "no.such.id" {
+		t.Errorf("an unknown id gave %q, want the id itself", got)
+	}
+}
+
+func TestUnknownLocaleIsEnglish(t *testing.T) {
+	t.Cleanup(func() { setLocale(defaultLocale) })
+	setLocale("xx")
+	if tr("won.title") != fallbackMessages["won.title"] {
+		t.Errorf("an unknown locale gave %q", tr("won.title"))
+	}
+}
+
+func TestConsoleIsTranslated(t *testing.T) {
+	t.Cleanup(func() { setLocale(defaultLocale) })
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	setLocale("es")
+	tap(t, g, in, ebiten.KeyTab)
+	runConsole(t, g, in, "frobnicate", "rm x")
+	out := g.commandOutput
+	if !slices.Contains(out, "frobnicate: COMANDO NO ENCONTRADO") {
+		t.Errorf("unknown command in es printed %q", out)
+	}
+	if !slices.Contains(out, "rm: PERMISO DENEGADO EN MODO SAFE") {
+		t.Errorf("rm in SAFE in es printed %q", out)
+	}
+
+	if _, err := validateTarget("", false, targetRules{}); err == nil || err.Error() != "NO SE INDICÓ NINGÚN DIRECTORIO" {
+		t.Errorf("an empty target in es gave %v", err)
+	}
+}
+
+func TestEnvLocale(t *testing.T) {
+	for _, c := range []struct{ termiwar, lang, want string }{
+		{"", "es_ES.UTF-8", "es"},
+		{"", "C", "c"},
+		{"en", "es_ES.UTF-8", "en"},
+		{"", "", ""},
+	} {
+		t.Setenv("TERMIWAR_LANG", c.termiwar)
+		t.Setenv("LANG", c.lang)
+		if got := envLocale(); got != c.want {
+			t.Errorf("TERMIWAR_LANG=%q LANG=%q gave %q, want %q", c.termiwar, c.lang, got, c.want)
+		}
+	}
+}
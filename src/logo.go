@@ -0,0 +1,49 @@
+package main
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+//go:embed logo.txt
+var logoText string
+
+var logoLines = strings.Split(strings.TrimRight(logoText, "\n"), "\n")
+
+// logoRows is how many text rows the banner takes, including a blank row
+// under it
+var logoRows = len(logoLines) + 1
+
+// logoFits reports whether there's room for the banner above the menu
+func (g *Game) logoFits() bool {
+	return g.rowsFrom(0) >= logoRows+6 && g.logoWidth() <= g.textWidth()
+}
+
+func (g *Game) logoWidth() int {
+	width := 0
+	for _, line := range logoLines {
+		width = max(width, font.MeasureString(mplusNormalFont, line).Ceil())
+	}
+	return width
+}
+
+// drawLogo draws the banner centered at the top. A slow wave of brightness
+// rolls down it, between the theme's dim and foreground shades, unless
+// reduced motion is on.
+func (g *Game) drawLogo(screen *ebiten.Image) {
+	theme := g.theme()
+	x := (g.screenWidth - g.logoWidth()) / 2
+	now := float64(g.now().UnixMilli()) / 1000
+	for i, line := range logoLines {
+		clr := theme.Foreground
+		if !g.save.Settings.ReducedMotion {
+			clr = lerpColor(theme.Dim, theme.Foreground, 0.6+0.4*math.Sin(now*2-float64(i)*0.7))
+		}
+		text.Draw(screen, line, mplusNormalFont, x, g.lineY(i), clr)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// The sidebar takes this fraction of the window, and isn't drawn at all
+// when that would be too narrow to read
+const (
+	sidebarFraction = 0.2
+	sidebarMinWidth = 180
+)
+
+// outlineLine is one row of the sidebar
+type outlineLine struct {
+	depth   int
+	name    string
+	current bool
+}
+
+// dirOutline sketches where current sits in the tree under root: each
+// directory on the way down from root on its own indented row, then the
+// directories directly inside current one level deeper. Everything else
+// stays collapsed.
+func dirOutline(nodes []FSNode, root, current string) []outlineLine {
+	rel, err := filepath.Rel(root, current)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel, current = ".", root
+	}
+
+	lines := []outlineLine{{depth: 0, name: filepath.Base(root) + "/", current: current == root}}
+	if rel != "." {
+		for i, part := range strings.Split(rel, string(filepath.Separator)) {
+			lines = append(lines, outlineLine{depth: i + 1, name: part + "/"})
+		}
+		lines[len(lines)-1].current = true
+	}
+
+	depth := lines[len(lines)-1].depth + 1
+	for _, node := range nodes {
+		if node.IsDir && node.Parent == current && node.Path != current {
+			lines = append(lines, outlineLine{depth: depth, name: filepath.Base(node.Path) + "/"})
+		}
+	}
+	return lines
+}
+
+// sidebarWidth is how much room the outline takes on the left, 0 when hidden
+func (g *Game) sidebarWidth() int {
+	w := int(float64(g.screenWidth) * sidebarFraction)
+	if w < sidebarMinWidth {
+		return 0
+	}
+	return w
+}
+
+// drawSidebar draws the outline next to the listing. The current location is
+// the directory being browsed, or the selected node's directory in the flat
+// listing. Callers hold fsMutex.
+func (g *Game) drawSidebar(screen *ebiten.Image, selected *FSNode) {
+	width := g.sidebarWidth()
+	if width == 0 {
+		return
+	}
+	current := g.browseDir()
+	if current == "" && selected != nil {
+		current = selected.Parent
+		if selected.IsDir {
+			current = selected.Path
+		}
+	}
+
+	lines := dirOutline(g.fsNodes, g.finalFilesystemPath, current)
+	rows := g.visibleRows()
+	for i, line := range lines[:min(len(lines), rows)] {
+		clr := g.theme().Dim
+		prefix := "  "
+		if line.current {
+			clr = g.terminalColor
+			prefix = "> "
+		}
+		// Chop long names so they don't run into the listing
+		s := fitText(prefix+strings.Repeat(" ", line.depth)+line.name, width-g.marginX())
+		text.Draw(screen, s, mplusNormalFont, g.marginX(), g.lineY(2+i), clr)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDirOutline(t *testing.T) {
+	fsys := mustMemFS(`
+/t/a/b/deep/
+/t/a/c/
+/t/a/notes.txt 3
+/t/z/
+/t/f.txt 3
+`)
+	g, _ := testGame(t)
+	scan(t, g, fsys, "/t")
+
+	atRoot := []outlineLine{{0, "t/", true}, {1, "a/", false}, {1, "z/", false}}
+	tests := []struct {
+		current string
+		want    []outlineLine
+	}{
+		{"/t", atRoot},
+		{"/t/a", []outlineLine{{0, "t/", false}, {1, "a/", true}, {2, "b/", false}, {2, "c/", false}}},
+		{"/t/a/b", []outlineLine{{0, "t/", false}, {1, "a/", false}, {2, "b/", true}, {3, "deep/", false}}},
+		{"/t/a/b/deep", []outlineLine{{0, "t/", false}, {1, "a/", false}, {2, "b/", false}, {3, "deep/", true}}},
+		{"/elsewhere", atRoot}, // outside the target falls back to the root
+	}
+	for _, tt := range tests {
+		if got := dirOutline(g.fsNodes, "/t", tt.current); !slices.Equal(got, tt.want) {
+			t.Errorf("dirOutline at %s = %v, want %v", tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestSidebarWidthScales(t *testing.T) {
+	g, _ := testGame(t)
+	if got := g.sidebarWidth(); got != int(1920*sidebarFraction) {
+		t.Errorf("sidebar %dpx wide in a 1920px window", got)
+	}
+	g.screenWidth = 800
+	if got := g.sidebarWidth(); got != 0 {
+		t.Errorf("sidebar %dpx wide in an 800px window, want it hidden", got)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"image"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// hitTest is the index of the first rect containing pt, or -1 if it missed
+// them all
+func hitTest(rects []image.Rectangle, pt image.Point) int {
+	for i, r := range rects {
+		if pt.In(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// menuTop is the row the mode selection starts on, under the banner when
+// there's room for it
+func (g *Game) menuTop() int {
+	if g.logoFits() {
+		return logoRows
+	}
+	return 0
+}
+
+// modeLabelRects are the clickable areas of the mode labels, in modeNames
+// order. Each covers its slot on the row, as wide as the bracketed form, and
+// one line tall, so the spot to click doesn't move with the selection.
+func (g *Game) modeLabelRects() []image.Rectangle {
+	gap := font.MeasureString(mplusNormalFont, " ").Ceil()
+	metrics := mplusNormalFont.Metrics()
+	y := g.lineY(g.menuTop() + 2)
+	x := g.marginX()
+	rects := make([]image.Rectangle, len(modeNames))
+	for i, name := range modeNames {
+		w := text.BoundString(mplusNormalFont, "[ "+name+" ]").Dx()
+		rects[i] = image.Rect(x, y-metrics.Ascent.Ceil(), x+w, y+metrics.Descent.Ceil())
+		x += w + gap
+	}
+	return rects
+}
+
+// promptHintRect is the clickable area of the target prompt waiting under the
+// mode selection
+func (g *Game) promptHintRect() image.Rectangle {
+	metrics := mplusNormalFont.Metrics()
+	x, y := g.marginX(), g.lineY(g.menuTop()+4)
+	w := text.BoundString(mplusNormalFont, targetPrompt(g.currentMode)).Dx()
+	return image.Rect(x, y-metrics.Ascent.Ceil(), x+w, y+metrics.Descent.Ceil())
+}
+
+// recordModeRects keeps the mode label areas as drawn, so the next Update
+// hit-tests against what's actually on screen even after a resize
+func (g *Game) recordModeRects(rects []image.Rectangle) {
+	g.modeRects = append(g.modeRects[:0], rects...)
+}
+
+// updateMenuMouse highlights the mode label under the cursor and picks a mode
+// when one is clicked. Clicking the one that's already picked, or the prompt
+// under them, goes on to the prompt, the same as pressing Enter.
+func (g *Game) updateMenuMouse() {
+	g.hoverMode = hitTest(g.modeRects, g.input.Cursor())
+	pt, ok := g.input.Click()
+	if !ok {
+		return
+	}
+	i := hitTest(g.modeRects, pt)
+	switch {
+	case pt.In(g.promptRect):
+		g.chooseMode() // the dangerous modes still ask for YES first
+	case i < 0:
+		return
+	case Mode(i) == g.currentMode:
+		g.chooseMode()
+	default:
+		g.startColorTransition()
+		g.currentMode = Mode(i)
+	}
+}
+
+// caretAt is the caret position for a click at pt on a prompt drawn by
+// drawPrompt with the same prefix, x, y and maxWidth, the gap between
+// characters nearest to it. ok is false when pt isn't on the prompt.
+func (g *Game) caretAt(prefix string, x, y, maxWidth int, pt image.Point) (caret int, ok bool) {
+	lines := g.promptLines(prefix, maxWidth)
+	top := y - mplusNormalFont.Metrics().Ascent.Ceil()
+	row := (pt.Y - top) / lineHeight()
+	if pt.Y < top || row >= len(lines) || pt.X < x {
+		return 0, false
+	}
+
+	pos := 0
+	for _, line := range lines[:row] {
+		pos += utf8.RuneCountInString(line)
+	}
+	runes := []rune(lines[row])
+	col := 0
+	for col < len(runes) {
+		left := font.MeasureString(mplusNormalFont, string(runes[:col])).Ceil()
+		right := font.MeasureString(mplusNormalFont, string(runes[:col+1])).Ceil()
+		if pt.X-x < (left+right)/2 {
+			break
+		}
+		col++
+	}
+	prefixLen := utf8.RuneCountInString(prefix)
+	buffer := utf8.RuneCountInString(g.inputBuffer)
+	return max(0, min(pos+col-prefixLen, buffer)), true
+}
+
+// updatePromptMouse moves the caret to wherever the directory prompt was
+// clicked
+func (g *Game) updatePromptMouse() {
+	pt, ok := g.input.Click()
+	if !ok {
+		return
+	}
+	if caret, ok := g.caretAt(targetPrompt(g.currentMode), g.marginX(), g.lineY(0), g.textWidth(), pt); ok {
+		g.inputCaret = caret
+	}
+}
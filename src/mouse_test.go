@@ -0,0 +1,172 @@
+package main
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+func TestHitTest(t *testing.T) {
+	rects := []image.Rectangle{
+		image.Rect(10, 10, 50, 30),
+		image.Rect(60, 10, 120, 30),
+		image.Rect(40, 20, 80, 40), // overlaps both
+	}
+	for _, c := range []struct {
+		pt   image.Point
+		want int
+	}{
+		{image.Pt(10, 10), 0},
+		{image.Pt(49, 29), 0},
+		{image.Pt(50, 15), -1}, // Max is outside
+		{image.Pt(60, 10), 1},
+		{image.Pt(45, 25), 0}, // first one wins
+		{image.Pt(55, 35), 2},
+		{image.Pt(-1, -1), -1},
+		{image.Pt(200, 200), -1},
+	} {
+		if got := hitTest(rects, c.pt); got != c.want {
+			t.Errorf("hitTest(%v) = %d, want %d", c.pt, got, c.want)
+		}
+	}
+	if hitTest(nil, image.Pt(0, 0)) != -1 {
+		t.Error("hitTest with no rects hit something")
+	}
+}
+
+func center(r image.Rectangle) image.Point {
+	return r.Min.Add(r.Max).Div(2)
+}
+
+func TestClickModeLabel(t *testing.T) {
+	g, in := testGame(t)
+	g.recordModeRects(g.modeLabelRects())
+
+	in.clickAt(center(g.modeRects[ModeDanger]))
+	update(t, g, 1)
+	if g.currentMode != ModeDanger {
+		t.Fatalf("clicking DANGER picked %s", modeNames[g.currentMode])
+	}
+	if g.confirmActive || g.inputActive {
+		t.Fatal("the first click on a mode went past selecting it")
+	}
+
+	in.clickAt(image.Pt(0, 0))
+	update(t, g, 1)
+	if g.currentMode != ModeDanger {
+		t.Errorf("a click off the labels changed the mode to %s", modeNames[g.currentMode])
+	}
+
+	in.clickAt(center(g.modeRects[ModeSafe]))
+	update(t, g, 1)
+	in.clickAt(center(g.modeRects[ModeSafe]))
+	update(t, g, 1)
+	if !g.inputActive {
+		t.Error("clicking the selected SAFE label didn't open the prompt")
+	}
+}
+
+func TestRecordModeRects(t *testing.T) {
+	g, _ := testGame(t)
+	rects := g.modeLabelRects()
+	g.recordModeRects(rects)
+	if len(g.modeRects) != len(modeNames) {
+		t.Fatalf("recorded %d rects for %d modes", len(g.modeRects), len(modeNames))
+	}
+	rects[0] = image.Rectangle{}
+	if g.modeRects[0].Empty() {
+		t.Error("the recorded rects share the caller's slice")
+	}
+	for i := 1; i < len(g.modeRects); i++ {
+		if g.modeRects[i].Min.X < g.modeRects[i-1].Max.X {
+			t.Errorf("label %d starts inside label %d", i, i-1)
+		}
+	}
+
+	// A resize moves the labels, drawing records where they went
+	g.screenWidth, g.screenHeight = 800, 480
+	if slices.Equal(g.modeRects, g.modeLabelRects()) {
+		t.Fatal("the labels don't move on a resize, nothing to check")
+	}
+	g.Draw(ebiten.NewImage(g.screenWidth, g.screenHeight))
+	if !slices.Equal(g.modeRects, g.modeLabelRects()) {
+		t.Errorf("after a resize Draw recorded %v, the labels are at %v", g.modeRects, g.modeLabelRects())
+	}
+}
+
+func TestHoverModeLabel(t *testing.T) {
+	g, in := testGame(t)
+	g.recordModeRects(g.modeLabelRects())
+
+	in.cursor = center(g.modeRects[ModeDestruction])
+	update(t, g, 1)
+	if g.hoverMode != int(ModeDestruction) {
+		t.Errorf("hovering DESTRUCTION gave hoverMode %d", g.hoverMode)
+	}
+	if g.currentMode != ModeSafe {
+		t.Error("hovering changed the mode without a click")
+	}
+
+	in.cursor = image.Pt(-1, -1)
+	update(t, g, 1)
+	if g.hoverMode != -1 {
+		t.Errorf("hoverMode stayed %d with the mouse gone", g.hoverMode)
+	}
+}
+
+func TestClickPromptHint(t *testing.T) {
+	g, in := testGame(t)
+	g.promptRect = g.promptHintRect()
+	in.clickAt(center(g.promptRect))
+	update(t, g, 1)
+	if !g.inputActive {
+		t.Fatal("clicking the prompt on SAFE didn't open it")
+	}
+
+	g, in = testGame(t)
+	g.currentMode = ModeDanger
+	g.promptRect = g.promptHintRect()
+	in.clickAt(center(g.promptRect))
+	update(t, g, 1)
+	if !g.confirmActive || g.inputActive {
+		t.Error("clicking the prompt on DANGER skipped the YES check")
+	}
+}
+
+func TestClickPlacesCaret(t *testing.T) {
+	g, in := testGame(t)
+	openPrompt(t, g, in)
+	typeText(t, g, in, "/tmp/abc")
+	prefix := targetPrompt(g.currentMode)
+	y := g.lineY(0) - 2
+
+	// Just past the slash, so the caret goes in front of the a
+	x := g.marginX() + font.MeasureString(mplusNormalFont, prefix+"/tmp/").Ceil() + 1
+	in.clickAt(image.Pt(x, y))
+	update(t, g, 1)
+	if g.inputCaret != 5 {
+		t.Errorf("clicking before the a put the caret at %d, want 5", g.inputCaret)
+	}
+
+	// Over the prefix goes to the start, past the end goes to the end
+	in.clickAt(image.Pt(g.marginX()+1, y))
+	update(t, g, 1)
+	if g.inputCaret != 0 {
+		t.Errorf("clicking the prefix put the caret at %d, want 0", g.inputCaret)
+	}
+	in.clickAt(image.Pt(g.marginX()+g.textWidth()-1, y))
+	update(t, g, 1)
+	if g.inputCaret != 8 {
+		t.Errorf("clicking past the end put the caret at %d, want 8", g.inputCaret)
+	}
+
+	// Off the prompt leaves it alone
+	in.clickAt(image.Pt(x, g.lineY(4)))
+	update(t, g, 1)
+	if g.inputCaret != 8 {
+		t.Errorf("clicking under the prompt moved the caret to %d", g.inputCaret)
+	}
+}
@@ -1,21 +1,52 @@
 package main
 
 import (
+	"context"
+	_ "embed"
+	"flag"
+	"fmt"
 	"log"
-	"os"
+	"math/rand"
+	"slices"
 
+	"image"
 	"image/color"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/opentype"
 )
 
 type InitSequenceBootLine struct {
-	Text  string
-	Delay int // ms before showing line
+	Text           string
+	Delay          int     // ms before showing line
+	CharsPerSecond float64 // typing speed override, 0 uses bootCharsPerSecond
+}
+
+// Default typewriter speed for boot lines
+var bootCharsPerSecond = 40.0
+
+func (l InitSequenceBootLine) charsPerSecond() float64 {
+	if l.CharsPerSecond > 0 {
+		return l.CharsPerSecond
+	}
+	return bootCharsPerSecond
+}
+
+// With FAST BOOT on every pause and keystroke of a typed sequence takes this
+// fraction of the time
+const fastBootScale = 0.1
+
+// bootScale is what typed sequence timings get multiplied by right now
+func (g *Game) bootScale() float64 {
+	if g.save.Settings.FastBoot {
+		return fastBootScale
+	}
+	return 1
 }
 
 type Mode int
@@ -28,11 +59,36 @@ const (
 
 var modeNames = []string{"SAFE", "DESTRUCTION", "DANGER"}
 
+// Message ids for what the confirmation screen warns about in each mode,
+// SAFE doesn't need one
+var modeWarnings = map[Mode]string{
+	ModeDestruction: "warning.destruction",
+	ModeDanger:      "warning.danger",
+}
+
+// Shell prompt each mode types the target after, DESTRUCTION gets root's
+var modePromptSymbols = map[Mode]string{
+	ModeSafe:        "$",
+	ModeDestruction: "#",
+	ModeDanger:      "!",
+}
+
+// targetPrompt is what the target path is typed after, ending in the mode's
+// prompt symbol like a shell would
+func targetPrompt(mode Mode) string {
+	return tr("menu.target_prompt") + " " + modePromptSymbols[mode] + " "
+}
+
+//go:embed VT323-Regular.ttf
+var vt323FontData []byte
+
 var (
 	mplusNormalFont font.Face
 	hackerGreen     = color.RGBA{51, 255, 51, 255}
-	lowGlowGreen    = color.RGBA{0, 50, 0, 255} // For that background "hum"
-
+	lowGlowGreen    = color.RGBA{0, 50, 0, 255}  // For that background "hum"
+	dimGreen        = color.RGBA{0, 100, 0, 255} // Inactive menu items and list entries
+	warningRed      = color.RGBA{255, 51, 51, 255}
+	amberAccent     = color.RGBA{255, 176, 0, 255}
 )
 
 type GameState int
@@ -44,179 +100,1004 @@ const (
 	StatePlaying
 	StateWon
 	StateLoose
+	StateFSError
+	StatePaused
+	StateEngaging // announcing the chosen mode before the scan screen
+	StateSettings
+	StateAttract // the menu's self-playing demo
+	StateFault   // a panic was caught, see recoverFault
 )
 
+var stateNames = map[GameState]string{
+	StateBooting:  "BOOTING",
+	StateMenu:     "MENU",
+	StateFSInit:   "MOUNTING",
+	StatePlaying:  "PLAYING",
+	StateWon:      "WON",
+	StateLoose:    "LOST",
+	StateFSError:  "SCAN FAILED",
+	StatePaused:   "PAUSED",
+	StateEngaging: "ENGAGING",
+	StateSettings: "SETTINGS",
+	StateAttract:  "ATTRACT",
+	StateFault:    "FAULT",
+}
+
+func (s GameState) String() string {
+	return stateNames[s]
+}
+
 var bootSequence = []InitSequenceBootLine{
-	{"TERMI WAR V1.0.0", 500},
-	{"CORE-OS LOADING....", 850},
-	{"INITALIZING GRAPHICS DRIVERS.....", 400},
-	{"GRAPHICS: OK", 400},
-	{"INITALIZING CPU......", 500},
-	{"CPU: OK", 400},
-	{"MOUNTING FILESYSTEM...", 600},
-	{"SCANNING FOR NODES...", 1000},
-	{"WARNING: DESTRUCTION MODE DETECTED IN KERNEL", 500},
+	{"TERMI WAR V1.0.0", 500, 0},
+	{"CORE-OS LOADING....", 850, 0},
+	{"INITALIZING GRAPHICS DRIVERS.....", 400, 0},
+	{"GRAPHICS: OK", 400, 0},
+	{"INITALIZING CPU......", 500, 0},
+	{"CPU: OK", 400, 0},
+	{"MOUNTING FILESYSTEM...", 600, 0},
+	{"SCANNING FOR NODES...", 1000, 0},
+}
+
+// modeBootSequences swaps in a boot for whichever mode was played last, each
+// one a little more ominous than the one before
+var modeBootSequences = map[Mode][]InitSequenceBootLine{
+	ModeSafe: append(slices.Clone(bootSequence),
+		InitSequenceBootLine{"SANDBOX: ENABLED", 400, 0},
+		InitSequenceBootLine{"ALL SYSTEMS NOMINAL", 600, 0},
+	),
+	ModeDestruction: append(slices.Clone(bootSequence),
+		InitSequenceBootLine{"WRITE PROTECTION: DISABLED", 500, 0},
+		InitSequenceBootLine{"ARMING SUBROUTINES...", 1200, 20},
+		InitSequenceBootLine{"SUBROUTINES ARMED", 600, 0},
+	),
+	ModeDanger: append(slices.Clone(bootSequence),
+		InitSequenceBootLine{"WRITE PROTECTION: DISABLED", 500, 0},
+		InitSequenceBootLine{"INTRUSION COUNTERMEASURES: HOSTILE", 700, 0},
+		InitSequenceBootLine{"ARMING SUBROUTINES...", 1500, 12},
+		InitSequenceBootLine{"SUBROUTINES ARMED. THERE IS NO UNDO", 800, 0},
+	),
+}
+
+// bootSequenceFor picks the boot for the last mode in the save, or the plain
+// one before anything has been played. A custom boot from boot.json beats
+// them all.
+func bootSequenceFor(save *SaveData, custom []InitSequenceBootLine) []InitSequenceBootLine {
+	if custom != nil {
+		return custom
+	}
+	if mode, ok := save.lastMode(); ok {
+		return modeBootSequences[mode]
+	}
+	return bootSequence
+}
+
+// modeEngagedLine is typed out once a mode and target are chosen, SAFE gets
+// reassurance while the others get a warning
+func modeEngagedLine(mode Mode) InitSequenceBootLine {
+	switch mode {
+	case ModeDestruction:
+		return InitSequenceBootLine{"WARNING: MODE ENGAGED: DESTRUCTION", 300, 0}
+	case ModeDanger:
+		return InitSequenceBootLine{"WARNING: MODE ENGAGED: DANGER", 300, 12}
+	}
+	return InitSequenceBootLine{"MODE ENGAGED: SAFE. NO FILES WILL BE HARMED", 300, 0}
+}
+
+// How long the menu highlight takes to fade to a newly picked mode's color
+const colorTransitionTime = 150 * time.Millisecond
+
+// startColorTransition fades the highlight from wherever it is now, call it
+// just before currentMode changes
+func (g *Game) startColorTransition() {
+	g.colorTransitionFrom = g.menuAccent()
+	g.colorTransitionStart = g.now()
+}
+
+// menuAccent is the color of the selected mode in the menu, partway between
+// the previous mode's accent and this one's while a transition runs
+func (g *Game) menuAccent() color.RGBA {
+	target := g.modeAccent(g.currentMode)
+	t := float64(g.since(g.colorTransitionStart)) / float64(colorTransitionTime)
+	if g.save.Settings.ReducedMotion || t >= 1 {
+		return target
+	}
+	return lerpColor(g.colorTransitionFrom, target, t)
+}
+
+// modeAccent is the highlight color used once a mode has been engaged
+func (g *Game) modeAccent(mode Mode) color.RGBA {
+	switch mode {
+	case ModeDestruction:
+		return amberAccent
+	case ModeDanger:
+		return g.theme().Warning
+	}
+	return g.theme().Foreground
 }
 
 type Game struct {
 	state                   GameState
 	inputActive             bool
+	confirmActive           bool // waiting for YES before a dangerous mode
 	finalFilesystemPath     string
+	presetTarget            string       // from --target, skips the directory prompt
+	targetRules             targetRules  // directories never to scan, from targets.json
+	toasts                  []toast      // notices in the corner, oldest first
+	resuming                *RunSnapshot // applied once the resumed run's scan is done
+	windowTitle             string       // last one set, see updateWindowTitle
 	inputBuffer             string
+	inputCaret              int    // rune index into inputBuffer where typing goes
+	maxInput                int    // most runes inputBuffer takes, 0 for no limit
+	inputError              string // why the last submitted target was rejected
 	currentMode             Mode
+	modeRects               []image.Rectangle // mode labels as last drawn, see recordModeRects
+	promptRect              image.Rectangle   // the target prompt under them, as last drawn
+	hoverMode               int               // mode label under the mouse, -1 for none
 	bootIndex               int
+	bootTyping              bool        // current boot line is still being typed out
+	bootRevealed            int         // characters of the current boot line shown so far
+	tickLimit               tickLimiter // keeps the typewriter tick from machine-gunning
 	lastUpdate              time.Time
+	elapsed                 time.Duration // game clock, see tick
 	bootSquenceVisibleLines []string
+	bootLines               []InitSequenceBootLine // picked from the save when booting starts
+	customBoot              []InitSequenceBootLine // from boot.json, nil for the built-in boots
+	bootFaultLines          map[int]bool           // indices of bootLines that are injected errors
+	engageSequence          []InitSequenceBootLine
 	terminalColor           color.RGBA
-	lastInputTime           time.Time
+	lastInputTime           time.Time // last key press anywhere, for attract mode
+	colorTransitionStart    time.Time // menu highlight fade, see menuAccent
+	colorTransitionFrom     color.RGBA
+	attractIndex            int       // next step of attractScript
+	attractNext             time.Time // when that step happens
+	backspaceRepeat         keyRepeat
+	caretLeftRepeat         keyRepeat
+	caretRightRepeat        keyRepeat
+	modeLeftRepeat          keyRepeat // holding an arrow in the menu keeps cycling modes
+	modeRightRepeat         keyRepeat
+
+	// Scan results. The initalizeFilesystem goroutine writes these while Update
+	// and Draw read them, so every access to fsNodes (including the nodes
+	// themselves), fsNodeCount, fsStats, fsReady, fsErr, fsTruncated and
+	// fsVersion has to hold fsMutex: RLock to read, Lock to change anything.
+	fsMutex     sync.RWMutex
+	fsNodes     []FSNode
+	fsNodeCount int // nodes found by the scan, unlike fsStats this doesn't drop when nodes are removed
+	fsStats     FSStats
+	fsReady     bool
+	fsErr       error
+	fsTruncated bool // the scan hit scanLimits and stopped early
+	fsVersion   int  // bumped whenever nodes are added or removed, see nodesChanged
+	scanLimits  scanLimits
+	cancelScan  context.CancelFunc // stops the running scan, if there is one
+	faultReport string             // crash report path shown on the fault screen
+
+	// StatePlaying listing, both index into the filtered view not fsNodes
+	viewCache    []int // see viewNodes
+	viewCacheKey viewKey
+	selectedNode int
+	listOffset   int // first row shown in the listing
+	searchActive bool
+	filter       string // only nodes whose path contains this are listed
+	dirStack     []browseFrame
+	listSort     listSort // kept from run to run
+
+	// DESTRUCTION scoring, see scoreAction
+	score          int
+	combo          int
+	lastActionTime time.Time
+	energy         float64 // spent by actions, see spend
+	newBestScore   bool    // the run that just ended beat the saved best
+
+	shutdownOnce sync.Once
+
+	selectedSetting int
+	themeCursor     int // theme shown in the settings, may be a locked one
+
+	// StatePlaying command console
+	commandActive  bool
+	commandOutput  []string
+	cwd            string // console working directory inside the target
+	commandHistory []string
+	historyIndex   int                    // position in commandHistory while recalling, len means a fresh line
+	undoStack      []deletion             // dry run rm's, newest last
+	previewCache   map[string]filePreview // SAFE preview pane, by path
+
+	// CRT post-processing
+	offscreen *ebiten.Image
+
+	// DANGER mode corruption effect
+	glitch       *glitchState
+	glitchBuffer *ebiten.Image
+
+	debugOverlay bool
+	helpVisible  bool
+
+	// Screen shake, see shake()
+	shakeUntil     time.Time
+	shakeDuration  time.Duration
+	shakeMagnitude float64
+	shakeX, shakeY float64 // this tick's offset, see updateShake
+
+	keymap    Keymap
+	input     InputSource // live keyboard, or a replay
+	clipboard Clipboard
+	dryRun    bool // destructive commands only ever touch the in-memory model
+
+	bossActive bool // the fake shell is covering the game, see toggleBoss
+
+	// Every random gameplay decision draws from rng, which is reseeded from
+	// seed when a run starts so the same seed plays out the same way
+	seed int64
+	rng  *rand.Rand
+
+	// Progress persisted between runs
+	save        *SaveData
+	savePath    string // empty if there's nowhere to save to
+	runStart    time.Time
+	runDuration time.Duration // final play time of the last finished run
+
+	// Time spent paused, so run timers don't count it
+	pausedAt    time.Time
+	pausedTotal time.Duration
+
+	// Window size to go back to when leaving fullscreen
+	windowedWidth  int
+	windowedHeight int
+
+	// Logical screen size from the last Layout call
+	screenWidth  int
+	screenHeight int
 }
 
 func init() {
-	// 1. Read the font file
-	fontData, err := os.ReadFile("VT323-Regular.ttf")
-	if err != nil {
-		log.Fatal(err)
+	// 1. Parse the embedded font, falling back to a basic face if it's broken
+	vt323Font, fontErr = loadFont(vt323FontData)
+	if fontErr != nil {
+		log.Println("failed to load VT323, using fallback font:", fontErr)
 	}
 
-	// 2. Parse the font
-	tt, err := opentype.Parse(fontData)
-	if err != nil {
-		log.Fatal(err)
-	}
+	// 2. Create the main font face, other sizes come from getFace as needed
+	mplusNormalFont = getFace(normalFontSize)
+}
 
-	// 3. Create a font face (Adjust size here)
-	const dpi = 72
-	mplusNormalFont, err = opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    30,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		log.Fatal(err)
+// newGame sets up a game sitting at the menu with default keys, reading from
+// input. Nothing here touches the window, so a scripted InputSource can drive
+// Update without one.
+func newGame(input InputSource, save *SaveData) *Game {
+	g := &Game{
+		keymap:        defaultKeymap(),
+		input:         input,
+		clipboard:     systemClipboard{},
+		save:          save,
+		state:         StateMenu,
+		dryRun:        true,
+		seed:          time.Now().UnixNano(),
+		lastUpdate:    gameEpoch,
+		lastInputTime: gameEpoch,
+		scanLimits:    defaultScanLimits,
+		hoverMode:     -1,
+		maxInput:      defaultMaxInput,
+
+		tickLimit:       newTickLimiter(maxTicksPerSecond),
+		modeLeftRepeat:  keyRepeat{interval: menuInterval},
+		modeRightRepeat: keyRepeat{interval: menuInterval},
 	}
+	g.terminalColor = g.theme().Foreground
+	return g
 }
 
 func (g *Game) Update() error {
+	defer g.recoverFault("Update")
+	return g.update()
+}
+
+func (g *Game) update() error {
+	// The close button only asks, we get to save before going
+	if ebiten.IsWindowBeingClosed() {
+		g.shutdown()
+		return ebiten.Termination
+	}
+	defer g.updateWindowTitle() // after this frame's state changes
+	g.input.Tick()
+	// The boss key works from anywhere, even mid-prompt. While the fake
+	// shell is up nothing else runs, not even the clock.
+	if g.justPressed(ActionBoss) {
+		g.toggleBoss()
+		return nil
+	}
+	if g.bossActive {
+		return nil
+	}
+	g.tick()
+	g.updateShake()
+	g.expireToasts()
+	if _, clicked := g.input.Click(); clicked || g.input.AnyJustPressed() {
+		g.lastInputTime = g.now()
+	}
+	// While help is up the game carries on underneath, it just doesn't get
+	// any keys until the overlay is dismissed
+	if g.helpVisible {
+		if g.helpPressed() || g.justPressed(ActionCancel) {
+			g.helpVisible = false
+		}
+		live := g.input
+		g.input = noInput{}
+		defer func() { g.input = live }()
+	} else if g.helpPressed() {
+		g.helpVisible = true
+		return nil
+	}
+
+	if g.justPressed(ActionDebug) {
+		g.debugOverlay = !g.debugOverlay
+	}
+	if g.justPressed(ActionFullscreen) {
+		g.toggleFullscreen()
+	}
+
+	// Mute toggles sound everywhere except while typing, where it's just a letter
+	if g.justPressed(ActionMute) && !g.typing() {
+		g.toggleMute()
+		g.writeSaveFile()
+		if g.save.Settings.Muted {
+			g.pushToast(tr("toast.muted"), toastTime)
+		} else {
+			g.pushToast(tr("toast.unmuted"), toastTime)
+		}
+	}
+
 	switch g.state {
 	case StateBooting:
-		// If we haven't finished the sequence
-		if g.bootIndex < len(bootSequence) {
-			// Check if enough time has passed to show the next line
-			if time.Since(g.lastUpdate).Milliseconds() > int64(bootSequence[g.bootIndex].Delay) {
-				g.bootSquenceVisibleLines = append(g.bootSquenceVisibleLines, bootSequence[g.bootIndex].Text)
-				g.bootIndex++
-				g.lastUpdate = time.Now()
-			}
-		} else if time.Since(g.lastUpdate).Seconds() > 2 {
-			// Wait 2 seconds after finishing, then clear and move to Menu
+		// Any key skips the intro, otherwise wait 2 seconds after it finishes
+		skip := g.input.AnyJustPressed()
+		if skip || g.typeLines(g.bootLines) && g.since(g.lastUpdate).Seconds() > 2*g.bootScale() {
 			g.state = StateMenu
 			g.bootSquenceVisibleLines = []string{}
+			g.lastInputTime = g.now() // the idle clock starts once the menu is up
 		}
 	case StateMenu:
-		if !g.inputActive {
-			if ebiten.IsKeyPressed(ebiten.KeyRight) && time.Now().Sub(g.lastInputTime).Milliseconds() >= 100 {
+		if g.tutorialShowing() {
+			g.updateTutorial()
+			return nil
+		}
+		if g.confirmActive {
+			g.updateConfirm()
+			return nil
+		}
 
-				println("Right key pressed")
-				g.lastInputTime = time.Now()
+		if !g.inputActive {
+			if g.since(g.lastInputTime) > attractIdle {
+				g.startAttract()
+				return nil
+			}
+			if g.repeating(&g.modeRightRepeat, ActionMoveRight) {
+				g.startColorTransition()
 				g.currentMode = (g.currentMode + 1) % Mode(len(modeNames))
 			}
-			if ebiten.IsKeyPressed(ebiten.KeyLeft) && time.Now().Sub(g.lastInputTime).Milliseconds() >= 100 {
-				println("Left key pressed")
-				g.lastInputTime = time.Now()
+			if g.repeating(&g.modeLeftRepeat, ActionMoveLeft) {
+				g.startColorTransition()
 				g.currentMode = (g.currentMode - 1 + Mode(len(modeNames))) % Mode(len(modeNames))
 			}
-			if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-				// If they pick DANGER or DESTRUCTION, you could trigger your warning here
-				g.inputActive = true
-				println("Enter pressed")
+			if g.justPressed(ActionConfirm) {
+				g.chooseMode()
+			}
+			g.updateMenuMouse()
+			if g.justPressed(ActionSettings) {
+				g.selectedSetting = 0
+				g.themeCursor = themeIndex(g.theme().Name)
+				g.state = StateSettings
+			}
+			if g.save.Resume != nil && g.justPressed(ActionResume) {
+				g.playSound(confirmSound)
+				g.resumeRun()
+				return nil
+			}
+			if g.justPressed(ActionReboot) {
+				g.startBoot()
+			}
+			if g.justPressed(ActionQuit) {
+				// Flush progress first, Termination makes RunGame return nil
+				g.shutdown()
+				return ebiten.Termination
 			}
 			return nil
 		}
 
 		// PHASE 2: Capturing Keyboard Input (Filtered)
-		// Capture characters (skips arrows/enter/etc automatically)
-		var b []rune
-		b = ebiten.AppendInputChars(b)
-		g.inputBuffer += string(b)
-
-		// Manual handling for Backspace
-		if ebiten.IsKeyPressed(ebiten.KeyBackspace) && len(g.inputBuffer) > 0 {
-			g.inputBuffer = g.inputBuffer[:len(g.inputBuffer)-1]
-		}
+		g.updateTextInput()
+		g.updatePromptMouse()
 
 		// Handle Enter to finish directory input
-		if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-			g.finalFilesystemPath = g.inputBuffer
-			go initalizeFilesystem()
+		if g.justPressed(ActionConfirm) {
+			path, err := validateTarget(g.inputBuffer, g.save.Settings.FileTargets, g.targetRules)
+			if err != nil {
+				g.inputError = err.Error()
+				return nil
+			}
+			g.inputError = ""
+			g.playSound(confirmSound)
+			g.engage(path)
+		}
+	case StateAttract:
+		g.updateAttract()
+	case StateEngaging:
+		if g.typeLines(g.engageSequence) && g.since(g.lastUpdate).Seconds() > 1 {
+			g.bootSquenceVisibleLines = []string{}
 			g.state = StateFSInit
 		}
+	case StateFSInit:
+		// Escape gives up on the scan and goes back to pick another target
+		if g.justPressed(ActionCancel) {
+			g.cancelScan()
+			g.resetFilesystem()
+			g.returnToMenu()
+			return nil
+		}
+		failed, ready := g.scanStatus()
+		if failed || ready {
+			g.cancelScan() // the walk is over, this just releases the context
+		}
+		if failed {
+			g.state = StateFSError
+		} else if ready {
+			g.startPlaying()
+		}
+	case StatePlaying:
+		g.updateEnergy()
+		g.updatePlaying()
+		g.glitch.update(g.glitchEnabled())
+		if g.checkLose() {
+			g.state = StateLoose
+			g.finishRun(false)
+		} else if g.checkWin() {
+			g.state = StateWon
+			g.finishRun(true)
+		}
+	case StateSettings:
+		g.updateSettings()
+	case StatePaused:
+		if g.justPressed(ActionPause) {
+			g.resume()
+		} else if g.justPressed(ActionAbandon) {
+			g.abandonRun()
+		}
+	case StateWon, StateLoose:
+		if g.justPressed(ActionConfirm) {
+			g.returnToMenu()
+		}
+	case StateFault:
+		g.updateFault()
+	case StateFSError:
+		// Back to mode selection to pick another directory
+		if g.justPressed(ActionConfirm) {
+			g.returnToMenu()
+		}
 	}
 
 	return nil
 }
 
-func initalizeFilesystem() {
+// typeLines advances the typewriter through lines, one character at a time
+// with each line's delay in between, and reports when every line is shown.
+// Progress lives in bootIndex/bootTyping/bootRevealed and the text in bootSquenceVisibleLines.
+func (g *Game) typeLines(lines []InitSequenceBootLine) bool {
+	// If we haven't finished the sequence
+	if g.bootIndex < len(lines) {
+		line := lines[g.bootIndex]
+		if !g.bootTyping {
+			// Check if enough time has passed to start the next line
+			if g.since(g.lastUpdate).Seconds()*1000 > float64(line.Delay)*g.bootScale() {
+				g.bootSquenceVisibleLines = append(g.bootSquenceVisibleLines, "")
+				g.bootTyping = true
+				g.bootRevealed = 0
+				g.lastUpdate = g.now()
+				g.playSound(bootBeepSound)
+			}
+		} else {
+			// Type out as many characters as the elapsed time allows
+			chars := []rune(line.Text)
+			revealed := min(len(chars), int(g.since(g.lastUpdate).Seconds()*line.charsPerSecond()/g.bootScale()))
+			if revealed > g.bootRevealed {
+				g.typewriterTick()
+			}
+			g.bootRevealed = revealed
+			g.bootSquenceVisibleLines[len(g.bootSquenceVisibleLines)-1] = string(chars[:g.bootRevealed])
+			if g.bootRevealed == len(chars) {
+				// Line finished, the next line's delay starts now
+				g.bootTyping = false
+				g.bootIndex++
+				g.lastUpdate = g.now()
+			}
+		}
+	}
+	return g.bootIndex >= len(lines)
+}
+
+// startTypewriter clears the screen for a fresh sequence of typed lines
+func (g *Game) startTypewriter() {
+	g.bootIndex = 0
+	g.bootTyping = false
+	g.bootSquenceVisibleLines = []string{}
+	g.bootFaultLines = nil
+	g.lastUpdate = g.now()
+}
+
+// updateTextInput feeds typed characters, pastes and backspaces into inputBuffer
+func (g *Game) updateTextInput() {
+	// Capture characters (skips arrows/enter/etc automatically)
+	b := typedChars(append([]rune{}, g.input.Chars()...))
+	g.inputCaret = max(0, min(g.inputCaret, utf8.RuneCountInString(g.inputBuffer)))
+	typed, full := fitInput(g.inputBuffer, string(b), g.maxInput)
+	g.inputBuffer, g.inputCaret = insertAt(g.inputBuffer, g.inputCaret, typed)
+	if typed != "" {
+		// the console is a teletype, everywhere else is a keyboard
+		if g.commandActive {
+			g.typewriterTick()
+		} else {
+			g.playSound(keyClickSound)
+		}
+		g.inputError = ""
+	}
+
+	// Ctrl+V (or Cmd+V) pastes from the system clipboard
+	if g.input.JustPressed(ebiten.KeyV) && (g.input.Pressed(ebiten.KeyControl) || g.input.Pressed(ebiten.KeyMeta)) {
+		if pasted, ok := g.clipboard.Read(); ok {
+			var cut bool
+			pasted, cut = fitInput(g.inputBuffer, sanitizePaste(pasted), g.maxInput)
+			g.inputBuffer, g.inputCaret = insertAt(g.inputBuffer, g.inputCaret, pasted)
+			full = full || cut
+		}
+	}
+	if full {
+		g.pushToastOnce(tr("toast.input_full"), toastTime)
+	}
+
+	// Caret movement, arrows repeat like backspace does
+	if g.repeatingKey(&g.caretLeftRepeat, editLeft) {
+		g.inputCaret = caretLeft(g.inputBuffer, g.inputCaret)
+	}
+	if g.repeatingKey(&g.caretRightRepeat, editRight) {
+		g.inputCaret = caretRight(g.inputBuffer, g.inputCaret)
+	}
+	if g.input.JustPressed(editHome) {
+		g.inputCaret = 0
+	}
+	if g.input.JustPressed(editEnd) {
+		g.inputCaret = utf8.RuneCountInString(g.inputBuffer)
+	}
+
+	// Manual handling for Backspace, it eats whatever is before the caret.
+	// With Ctrl (Alt on macOS) held it takes the whole word.
+	if g.repeatingKey(&g.backspaceRepeat, editBackspace) {
+		if g.input.Pressed(ebiten.KeyControl) || g.input.Pressed(ebiten.KeyAlt) {
+			g.inputBuffer, g.inputCaret = deleteWordBefore(g.inputBuffer, g.inputCaret)
+		} else {
+			g.inputBuffer, g.inputCaret = deleteBefore(g.inputBuffer, g.inputCaret)
+		}
+	}
+}
+
+// setInput replaces the input line with s and parks the caret at its end
+func (g *Game) setInput(s string) {
+	g.inputBuffer = s
+	g.inputCaret = utf8.RuneCountInString(s)
+}
+
+// updateConfirm handles the "TYPE YES" gate in front of the dangerous modes.
+// Anything other than YES backs out to mode selection.
+func (g *Game) updateConfirm() {
+	if g.justPressed(ActionCancel) {
+		g.confirmActive = false
+		g.setInput("")
+		return
+	}
+
+	g.updateTextInput()
+
+	if g.justPressed(ActionConfirm) {
+		accepted := strings.EqualFold(strings.TrimSpace(g.inputBuffer), "YES")
+		g.confirmActive = false
+		g.setInput("")
+		if accepted {
+			g.playSound(confirmSound)
+			g.startPrompt()
+		}
+	}
+}
+
+// chooseMode goes ahead with the selected mode. DANGER and DESTRUCTION have
+// to be confirmed before the prompt.
+func (g *Game) chooseMode() {
+	if g.currentMode == ModeSafe {
+		g.startPrompt()
+	} else {
+		g.confirmActive = true
+		g.setInput("")
+	}
+	g.playSound(confirmSound)
+}
+
+// startPrompt asks for the target directory, or skips straight to the scan
+// when one was given with --target
+func (g *Game) startPrompt() {
+	if g.presetTarget != "" {
+		g.engage(g.presetTarget)
+		return
+	}
+	g.inputActive = true
+}
+
+// engage starts scanning an already validated path in the current mode
+func (g *Game) engage(path string) {
+	g.inputActive = false
+	g.finalFilesystemPath = path
+	g.resetFilesystem()
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancelScan = cancel
+	go g.initalizeFilesystem(ctx, diskFS{g.targetRules}, g.finalFilesystemPath)
 
+	// Announce the mode while the scan gets going
+	g.terminalColor = g.modeAccent(g.currentMode)
+	g.engageSequence = []InitSequenceBootLine{modeEngagedLine(g.currentMode)}
+	g.startTypewriter()
+	g.state = StateEngaging
+}
+
+// typing reports whether keystrokes are currently going into inputBuffer
+func (g *Game) typing() bool {
+	switch g.state {
+	case StateMenu:
+		return g.inputActive || g.confirmActive
+	case StatePlaying:
+		return g.commandActive || g.searchActive
+	}
+	return false
+}
+
+// returnToMenu drops the player back on mode selection with a clean prompt
+func (g *Game) returnToMenu() {
+	g.state = StateMenu
+	g.terminalColor = g.theme().Foreground
+	g.inputActive = false
+	g.confirmActive = false
+	g.setInput("")
+	g.inputError = ""
+	g.resuming = nil
+}
+
+// resetFilesystem clears out anything left over from a previous scan
+func (g *Game) resetFilesystem() {
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	g.fsNodes = nil
+	g.nodesChanged()
+	g.fsNodeCount = 0
+	g.fsStats = FSStats{}
+	g.fsReady = false
+	g.fsErr = nil
+	g.fsTruncated = false
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	// Fill background with a very dark green/black
-	screen.Fill(color.RGBA{0, 5, 0, 255})
+	defer g.recoverFault("Draw")
+	g.draw(screen)
+}
+
+func (g *Game) draw(screen *ebiten.Image) {
+	if g.bossActive {
+		g.drawBoss(screen)
+		return
+	}
+	g.drawFrame(screen)
+	if g.tutorialShowing() {
+		g.drawTutorial(screen)
+	}
+	if g.helpVisible {
+		g.drawHelp(screen)
+	}
+	g.drawToasts(screen)
+
+	// Always last so it sits on top of every effect
+	if g.debugOverlay {
+		g.drawDebugOverlay(screen)
+	}
+}
+
+func (g *Game) drawFrame(screen *ebiten.Image) {
+	dx, dy := g.shakeOffset()
+	glitching := g.glitch.active() && g.glitchEnabled()
+	scanlines := shadersAvailable && !g.save.Settings.DisableScanlines
+	if !scanlines && !glitching && dx == 0 && dy == 0 {
+		g.drawScene(screen)
+		return
+	}
+
+	// Compose everything offscreen first so the CRT pass sees the whole frame
+	// and a shake or glitch can move all of it at once
+	size := screen.Bounds().Size()
+	g.offscreen = offscreenImage(g.offscreen, size)
+	scene := g.offscreen
+	g.drawScene(scene)
+	if glitching {
+		g.glitchBuffer = offscreenImage(g.glitchBuffer, size)
+		g.glitchBuffer.Clear()
+		g.glitch.apply(g.glitchBuffer, scene)
+		scene = g.glitchBuffer
+	}
+
+	screen.Fill(g.theme().Background)
+	if scanlines {
+		drawCRT(screen, scene, dx, dy)
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(dx, dy)
+	screen.DrawImage(scene, op)
+}
+
+func (g *Game) drawScene(screen *ebiten.Image) {
+	// Fill background with the theme's very dark near-black
+	screen.Fill(g.theme().Background)
+	g.drawHum(screen)
+
+	switch g.state {
+	case StateBooting, StateEngaging:
+		// Draw lines in the terminal color, the theme color until a mode is engaged
+		row := 0
+		for i, line := range g.bootSquenceVisibleLines {
+			draw := drawWrappedText
+			if g.bootLineBold(i, line) {
+				draw = drawWrappedBold
+			}
+			row += draw(screen, line, mplusNormalFont, g.marginX(), g.lineY(row), g.textWidth(), g.bootLineColor(i))
+		}
+		// Once the boot is all typed a cursor blinks after it until the menu comes up
+		if g.state == StateBooting && g.bootIndex >= len(g.bootLines) && len(g.bootSquenceVisibleLines) > 0 && g.cursorVisible() {
+			last := wrapText(g.bootSquenceVisibleLines[len(g.bootSquenceVisibleLines)-1], mplusNormalFont, g.textWidth())
+			if len(last) > 0 {
+				x := g.marginX() + font.MeasureString(mplusNormalFont, last[len(last)-1]+" ").Ceil()
+				g.drawBlockCursor(screen, x, g.lineY(row-1), g.terminalColor)
+			}
+		}
+	case StateMenu:
+		g.drawMenu(screen)
+	case StateFSInit:
+		g.drawFSInit(screen)
+	case StatePlaying:
+		g.drawPlaying(screen)
+		if g.commandActive {
+			g.drawConsole(screen)
+		}
+	case StatePaused:
+		g.drawPlaying(screen)
+		g.drawPaused(screen)
+	case StateWon:
+		g.drawWon(screen)
+	case StateLoose:
+		g.drawLoose(screen)
+	case StateSettings:
+		g.drawSettings(screen)
+	case StateAttract:
+		g.drawAttract(screen)
+	case StateFSError:
+		g.drawFSError(screen)
+	case StateFault:
+		g.drawFault(screen)
+	}
 
-	// Draw lines in "Hacker Green"
-	//green := color.RGBA{51, 255, 51, 255}
-	for i, line := range g.bootSquenceVisibleLines {
-		text.Draw(screen, line, mplusNormalFont, 20, 20+(i*30), hackerGreen)
+	if g.dryRun && g.state != StateBooting {
+		g.drawDryRunWatermark(screen)
+	}
+	if fontErr != nil {
+		drawBold(screen, g.warn(tr("overlay.font_fallback")), mplusNormalFont, g.marginX(), g.screenHeight-g.marginY(), g.theme().Warning)
+	}
+}
+
+// drawDryRunWatermark reminds the player in the bottom right that nothing on
+// disk will be harmed
+func (g *Game) drawDryRunWatermark(screen *ebiten.Image) {
+	mark := tr("overlay.dry_run")
+	x := g.screenWidth - g.marginX() - font.MeasureString(mplusNormalFont, mark).Ceil()
+	y := g.screenHeight - g.marginY()
+	text.Draw(screen, mark, mplusNormalFont, x, y, g.theme().Dim)
+}
+
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	if g.confirmActive {
+		drawBold(screen, g.warn(fmt.Sprintf(tr("menu.confirm_warning"), tr(modeWarnings[g.currentMode]))), mplusNormalFont, g.marginX(), g.lineY(0), g.theme().Warning)
+		g.drawPrompt(screen, tr("menu.confirm_prompt"), g.marginX(), g.lineY(1), 0, g.theme().Foreground)
+		return
 	}
 
 	// 2. Draw the Input Line
 	if g.inputActive {
-		prompt := "ENTER TARGET DIRECTORY: " + g.inputBuffer
-		// Add a blinking cursor
-		if (time.Now().UnixMilli()/500)%2 == 0 {
-			prompt += "_"
+		rows := g.drawPrompt(screen, targetPrompt(g.currentMode), g.marginX(), g.lineY(0), g.textWidth(), g.theme().Foreground)
+		if g.inputError != "" {
+			drawBold(screen, g.warn(fmt.Sprintf(tr("menu.invalid_target"), g.inputError)), mplusNormalFont, g.marginX(), g.lineY(rows), g.theme().Warning)
 		}
-		text.Draw(screen, prompt, mplusNormalFont, 20, 30, hackerGreen)
-	} else {
-		text.Draw(screen, "SELECT DIFFICULTY: ", mplusNormalFont, 20, 30, hackerGreen)
-		startX := 30
-		for i, name := range modeNames {
-			displayColor := color.RGBA{0, 100, 0, 255} // Dim green for inactive
-			prefix := "  "
-			suffix := "  "
-
-			if Mode(i) == g.currentMode {
-				displayColor = hackerGreen // Bright green
-				prefix = "[ "
-				suffix = " ]"
-			}
+		return
+	}
+
+	// The banner goes on top when there's room, everything else moves down under it
+	top := g.menuTop()
+	if top > 0 {
+		g.drawLogo(screen)
+	}
+
+	theme := g.theme()
+	text.Draw(screen, tr("menu.select_mode"), mplusNormalFont, g.marginX(), g.lineY(top), theme.Foreground)
 
-			str := prefix + name + suffix
-			text.Draw(screen, str, mplusNormalFont, startX, 100, displayColor)
+	// Each mode gets a slot as wide as its bracketed form plus a space, so the
+	// row doesn't shift around as the selection moves
+	slots := g.modeLabelRects()
+	g.recordModeRects(slots)
+	for i, name := range modeNames {
+		selected := Mode(i) == g.currentMode
+		var displayColor color.Color = theme.Dim
+		if selected {
+			displayColor = g.menuAccent()
+		} else if i == g.hoverMode {
+			displayColor = theme.Foreground // lights up under the mouse, no fade so nothing moves
+		}
 
-			// Offset the next word based on string length
-			startX += len(str) * 12
+		draw := text.Draw
+		if selected {
+			draw = drawBold
 		}
+		draw(screen, modeLabel(name, selected, theme.Markers), mplusNormalFont, slots[i].Min.X, g.lineY(top+2), displayColor)
+	}
+
+	if best, ok := g.save.bestTime(g.currentMode); ok {
+		text.Draw(screen, fmt.Sprintf(tr("menu.best"), best.Round(10*time.Millisecond)), mplusNormalFont, g.marginX(), g.lineY(top+3), theme.Dim)
+	}
+	// The prompt the mode leads to, waiting to be clicked
+	text.Draw(screen, targetPrompt(g.currentMode), mplusNormalFont, g.marginX(), g.lineY(top+4), theme.Dim)
+	g.promptRect = g.promptHintRect()
+	text.Draw(screen, tr("menu.hints"), mplusNormalFont, g.marginX(), g.lineY(top+5), theme.Dim)
+	if snap := g.save.Resume; snap != nil {
+		text.Draw(screen, fmt.Sprintf(tr("menu.resume"), snap.Mode, snap.Target), mplusNormalFont, g.marginX(), g.lineY(top+6), g.theme().Foreground)
 	}
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+func (g *Game) drawFSInit(screen *ebiten.Image) {
+	g.fsMutex.RLock()
+	count, truncated := g.fsNodeCount, g.fsTruncated
+	g.fsMutex.RUnlock()
 
-	return 1920, 1080
+	// Spinner frame comes straight from the clock so Draw doesn't need any extra state
+	frame := spinnerFrames[(g.now().UnixMilli()/100)%int64(len(spinnerFrames))]
+	text.Draw(screen, fmt.Sprintf(tr("fsinit.mounting"), g.finalFilesystemPath, frame), mplusNormalFont, g.marginX(), g.lineY(0), g.terminalColor)
+	if count > 0 {
+		text.Draw(screen, fmt.Sprintf(tr("fsinit.scanned"), count), mplusNormalFont, g.marginX(), g.lineY(1), g.terminalColor)
+	}
+	g.drawProgressBar(screen, g.marginX(), g.lineY(2), count, g.scanEstimate())
+	if truncated {
+		drawBold(screen, g.warn(fmt.Sprintf(tr("scan.truncated"), count)), mplusNormalFont, g.marginX(), g.lineY(3), g.theme().Warning)
+	}
+	text.Draw(screen, tr("fsinit.cancel"), mplusNormalFont, g.marginX(), g.lineY(5), g.theme().Dim)
+}
+
+func (g *Game) drawFSError(screen *ebiten.Image) {
+	g.fsMutex.RLock()
+	err := g.fsErr
+	g.fsMutex.RUnlock()
+	drawBold(screen, g.warn(fmt.Sprintf(tr("fserror.failed"), err)), mplusNormalFont, g.marginX(), g.lineY(0), g.theme().Warning)
+	text.Draw(screen, tr("fserror.retry"), mplusNormalFont, g.marginX(), g.lineY(1), g.theme().Foreground)
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	// Track the real window so Draw can lay things out relative to it
+	g.screenWidth, g.screenHeight = outsideWidth, outsideHeight
+	return outsideWidth, outsideHeight
 }
 
 func main() {
-	println("Starting OVERLORD...")
+	dryRun := flag.Bool("dry-run", true, "simulate destructive commands in memory instead of touching real files")
+	target := flag.String("target", "", "directory to scan, skips the prompt in the menu")
+	seed := flag.Int64("seed", 0, "seed for the run's randomness, 0 picks one from the clock")
+	maxDepth := flag.Int("max-depth", defaultScanLimits.MaxDepth, "deepest directory level to scan below the target, 0 for no limit")
+	maxNodes := flag.Int("max-nodes", defaultScanLimits.MaxNodes, "stop scanning after this many nodes, 0 for no limit")
+	maxInput := flag.Int("max-input", defaultMaxInput, "longest line the prompt and console take, in characters, 0 for no limit")
+	record := flag.String("record", "", "write every key press of the session to this file")
+	replay := flag.String("replay", "", "play back a file written by --record, with the seed it was recorded with")
+	flag.Parse()
+	setLocale(envLocale())
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
 	ebiten.SetWindowSize(1920, 1080)
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
-	ebiten.SetWindowTitle("Termi-War")
-	if err := ebiten.RunGame(&Game{
-		state:         StateMenu,
-		terminalColor: color.RGBA{51, 255, 51, 255},
-		lastUpdate:    time.Now(),
-		lastInputTime: time.Now(),
-	}); err != nil {
+	ebiten.SetWindowTitle(baseWindowTitle)
+	setWindowIcon()
+	ebiten.SetWindowClosingHandled(true)
+	initAudio()
+	savePath, err := saveFilePath()
+	if err != nil {
+		log.Println("no config directory, progress won't be saved:", err)
+	}
+	save := newSaveData()
+	if savePath != "" {
+		if loaded, err := loadSave(savePath); err != nil {
+			log.Println("failed to load save, starting fresh:", err)
+		} else {
+			save = loaded
+		}
+	}
+
+	if save.Settings.Fullscreen {
+		ebiten.SetFullscreen(true)
+	}
+
+	var rules targetRules
+	if path, err := configFile("targets.json"); err == nil {
+		if rules, err = loadTargetRules(path); err != nil {
+			log.Println("bad targets.json, nothing is blocked:", err)
+		}
+	}
+
+	var presetTarget string
+	if *target != "" {
+		if presetTarget, err = validateTarget(*target, save.Settings.FileTargets, rules); err != nil {
+			log.Println("ignoring --target:", err)
+		}
+	}
+
+	var customBoot []InitSequenceBootLine
+	if path, err := configFile("boot.json"); err == nil {
+		if customBoot, err = loadBootFile(path); err != nil {
+			log.Println("bad boot.json, using the built-in boot:", err)
+		}
+	}
+
+	keymap := defaultKeymap()
+	if path, err := configFile("keybindings.json"); err == nil {
+		if keymap, err = loadKeymap(path); err != nil {
+			log.Println("bad keybindings.json, using defaults:", err)
+			keymap = defaultKeymap()
+		}
+	}
+
+	var input InputSource = ebitenInput{}
+	var recorder *recordInput
+	switch {
+	case *replay != "":
+		replaying, err := loadReplay(*replay)
+		if err != nil {
+			log.Fatal("failed to load replay: ", err)
+		}
+		*seed = replaying.seed // anything else and the run plays out differently
+		input = replaying
+	case *record != "":
+		if recorder, err = recordToFile(*record, *seed); err != nil {
+			log.Fatal("failed to start recording: ", err)
+		}
+		input = recorder
+	}
+
+	g := newGame(input, save)
+	g.keymap = keymap
+	g.dryRun = *dryRun
+	g.savePath = savePath
+	g.seed = *seed
+	g.presetTarget = presetTarget
+	g.targetRules = rules
+	g.customBoot = customBoot
+	g.scanLimits = scanLimits{MaxDepth: *maxDepth, MaxNodes: *maxNodes}
+	g.maxInput = *maxInput
+	g.startBoot() // after the seed and boot.json are in, both shape the boot
+	g.applyFrameRate()
+	err = ebiten.RunGame(g)
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			log.Println("failed to save recording:", err)
+		}
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestModeCycle(t *testing.T) {
+	g, in := testGame(t)
+	// each tap advances one mode, waiting out the menu repeat in between
+	for _, want := range []Mode{ModeDestruction, ModeDanger, ModeSafe} {
+		tap(t, g, in, ebiten.KeyRight)
+		if g.currentMode != want {
+			t.Fatalf("right: mode %v, want %v", modeNames[g.currentMode], modeNames[want])
+		}
+		update(t, g, 5)
+	}
+	for _, want := range []Mode{ModeDanger, ModeDestruction, ModeSafe} {
+		tap(t, g, in, ebiten.KeyLeft)
+		if g.currentMode != want {
+			t.Fatalf("left: mode %v, want %v", modeNames[g.currentMode], modeNames[want])
+		}
+		update(t, g, 5)
+	}
+}
+
+func TestHeldEnterFiresOnce(t *testing.T) {
+	g, in := testGame(t)
+	in.press(ebiten.KeyEnter)
+	update(t, g, 10)
+	if !g.inputActive {
+		t.Fatal("enter didn't open the prompt")
+	}
+	if g.state != StateMenu {
+		t.Fatalf("held enter went on to %v, want the prompt to wait for another press", g.state)
+	}
+}
+
+func TestConfirmDangerousModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   Mode
+		typed  string // "" skips straight to Enter
+		prompt bool   // ends up at the target prompt
+	}{
+		{"safe skips the gate", ModeSafe, "", true},
+		{"destruction accepted", ModeDestruction, "yes", true},
+		{"danger accepted", ModeDanger, " YES ", true},
+		{"destruction rejected", ModeDestruction, "no", false},
+		{"danger rejected", ModeDanger, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, in := testGame(t)
+			g.currentMode = tt.mode
+			tap(t, g, in, ebiten.KeyEnter)
+			if tt.mode != ModeSafe {
+				if !g.confirmActive {
+					t.Fatal("no confirmation for a dangerous mode")
+				}
+				if tt.typed != "" {
+					typeText(t, g, in, tt.typed)
+				}
+				tap(t, g, in, ebiten.KeyEnter)
+			}
+			if g.confirmActive {
+				t.Fatal("still confirming")
+			}
+			if g.inputActive != tt.prompt {
+				t.Fatalf("at the prompt = %v, want %v", g.inputActive, tt.prompt)
+			}
+		})
+	}
+}
+
+func TestModeEngagedLine(t *testing.T) {
+	tests := []struct {
+		mode    Mode
+		text    string
+		warning bool
+	}{
+		{ModeDestruction, "WARNING: MODE ENGAGED: DESTRUCTION", true},
+		{ModeDanger, "WARNING: MODE ENGAGED: DANGER", true},
+		{ModeSafe, "MODE ENGAGED: SAFE. NO FILES WILL BE HARMED", false},
+	}
+	for _, tt := range tests {
+		line := modeEngagedLine(tt.mode)
+		if line.Text != tt.text {
+			t.Errorf("mode %v: line %q, want %q", tt.mode, line.Text, tt.text)
+		}
+		if got := strings.HasPrefix(line.Text, "WARNING"); got != tt.warning {
+			t.Errorf("mode %v: warning %v, want %v", tt.mode, got, tt.warning)
+		}
+	}
+}
+
+func TestEngageUsesModeAccent(t *testing.T) {
+	g, _ := testGame(t)
+	for _, mode := range []Mode{ModeDestruction, ModeDanger, ModeSafe} {
+		g.currentMode = mode
+		g.engage(t.TempDir())
+		g.cancelScan()
+		if g.terminalColor != g.modeAccent(mode) {
+			t.Errorf("mode %v: terminal color %v, want %v", mode, g.terminalColor, g.modeAccent(mode))
+		}
+		if len(g.engageSequence) != 1 || g.engageSequence[0] != modeEngagedLine(mode) {
+			t.Errorf("mode %v: engage sequence %v", mode, g.engageSequence)
+		}
+	}
+}
+
+func TestRebootFromMenu(t *testing.T) {
+	g, in := testGame(t)
+	g.startBoot()
+	update(t, g, 600)
+	if len(g.bootSquenceVisibleLines) == 0 {
+		t.Fatal("ten seconds of boot typed nothing")
+	}
+	g.state = StateMenu // as if the boot had played out
+
+	tap(t, g, in, ebiten.KeyB)
+	if g.state != StateBooting {
+		t.Fatalf("B went to %v, want booting", g.state)
+	}
+	if len(g.bootSquenceVisibleLines) != 0 || g.bootIndex != 0 {
+		t.Errorf("reboot kept %d visible lines at index %d", len(g.bootSquenceVisibleLines), g.bootIndex)
+	}
+	if g.since(g.lastUpdate) > tickLength() {
+		t.Errorf("boot timing started %v ago, want it restarted", g.since(g.lastUpdate))
+	}
+}
+
+func TestPresetTargetSkipsPrompt(t *testing.T) {
+	g, in := testGame(t)
+	target, err := validateTarget(t.TempDir(), false, targetRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.presetTarget = target
+
+	tap(t, g, in, ebiten.KeyEnter) // SAFE needs no confirmation
+	defer g.cancelScan()
+	if g.inputActive {
+		t.Error("the directory prompt opened despite --target")
+	}
+	if g.finalFilesystemPath != target {
+		t.Errorf("finalFilesystemPath %q, want %q", g.finalFilesystemPath, target)
+	}
+	if g.state != StateEngaging {
+		t.Errorf("state %v, want engaging", g.state)
+	}
+}
+
+func TestBootSequenceFor(t *testing.T) {
+	save := newSaveData()
+	if got := bootSequenceFor(save, nil); !slices.Equal(got, bootSequence) {
+		t.Error("a fresh save didn't get the default boot")
+	}
+	for _, mode := range []Mode{ModeSafe, ModeDestruction, ModeDanger} {
+		save.LastMode = modeNames[mode]
+		got := bootSequenceFor(save, nil)
+		if !slices.Equal(got, modeBootSequences[mode]) {
+			t.Errorf("last mode %v didn't get its own boot", modeNames[mode])
+		}
+		if !slices.Equal(got[:len(bootSequence)], bootSequence) {
+			t.Errorf("%v boot doesn't start with the generic lines", modeNames[mode])
+		}
+		arming := slices.ContainsFunc(got, func(l InitSequenceBootLine) bool {
+			return strings.HasPrefix(l.Text, "ARMING SUBROUTINES")
+		})
+		if want := mode != ModeSafe; arming != want {
+			t.Errorf("%v boot arming subroutines: %v, want %v", modeNames[mode], arming, want)
+		}
+	}
+
+	custom := []InitSequenceBootLine{{"HELLO", 0, 0}}
+	if got := bootSequenceFor(save, custom); !slices.Equal(got, custom) {
+		t.Error("boot.json didn't override the mode's boot")
+	}
+
+	save.LastMode = "BOGUS"
+	if got := bootSequenceFor(save, nil); !slices.Equal(got, bootSequence) {
+		t.Error("an unknown saved mode didn't fall back to the default boot")
+	}
+}
+
+func TestBootPlaysIntoMenu(t *testing.T) {
+	g, in := testGame(t)
+	g.startBoot()
+	for i := 0; i < 60*60 && g.state == StateBooting; i++ {
+		update(t, g, 1)
+	}
+	if g.state != StateMenu {
+		t.Fatalf("boot ended in %v, want the menu", g.state)
+	}
+
+	g.startBoot()
+	tap(t, g, in, ebiten.KeySpace)
+	if g.state != StateMenu {
+		t.Errorf("a key press left the boot in %v, want the menu", g.state)
+	}
+}
+
+// bootTicks plays the boot untouched and counts the frames until the menu
+func bootTicks(t *testing.T, fast bool) int {
+	t.Helper()
+	g, _ := testGame(t)
+	g.save.Settings.FastBoot = fast
+	g.startBoot()
+	ticks := 0
+	for ; ticks < 60*120 && g.state == StateBooting; ticks++ {
+		update(t, g, 1)
+	}
+	if g.state != StateMenu {
+		t.Fatalf("fast boot %v ended in %v, want the menu", fast, g.state)
+	}
+	return ticks
+}
+
+func TestFastBootIsFaster(t *testing.T) {
+	normal, fast := bootTicks(t, false), bootTicks(t, true)
+	// The scale covers delays, typing and the pause at the end alike
+	if limit := int(float64(normal)*fastBootScale) + 10; fast > limit {
+		t.Errorf("fast boot took %d frames against %d, want at most %d", fast, normal, limit)
+	}
+}
+
+func TestMenuAccentEases(t *testing.T) {
+	g, in := testGame(t)
+	from := g.modeAccent(ModeSafe)
+	tap(t, g, in, ebiten.KeyRight)
+	to := g.modeAccent(ModeDestruction)
+	if got := g.menuAccent(); got == to {
+		t.Errorf("accent snapped straight to %v", to)
+	}
+	update(t, g, 3)
+	if got := g.menuAccent(); got == from || got == to {
+		t.Errorf("accent %v 50ms in, want partway from %v to %v", got, from, to)
+	}
+	update(t, g, int(colorTransitionTime/tickLength())+1)
+	if got := g.menuAccent(); got != to {
+		t.Errorf("accent %v once the transition is over, want %v", got, to)
+	}
+
+	g.save.Settings.ReducedMotion = true
+	update(t, g, 5)
+	tap(t, g, in, ebiten.KeyLeft)
+	if got := g.menuAccent(); got != from {
+		t.Errorf("reduced motion accent %v, want it to snap to %v", got, from)
+	}
+}
+
+func TestTargetPromptSymbols(t *testing.T) {
+	for mode, want := range map[Mode]string{
+		ModeSafe:        "ENTER TARGET DIRECTORY $ ",
+		ModeDestruction: "ENTER TARGET DIRECTORY # ",
+		ModeDanger:      "ENTER TARGET DIRECTORY ! ",
+	} {
+		if got := targetPrompt(mode); got != want {
+			t.Errorf("targetPrompt(%s) = %q, want %q", modeNames[mode], got, want)
+		}
+	}
+}
+
+// holdCount holds key for frames updates and counts how often the mode changed
+func holdCount(t *testing.T, g *Game, in *fakeInput, key ebiten.Key, frames int) int {
+	t.Helper()
+	changes := 0
+	in.press(key)
+	for range frames {
+		before := g.currentMode
+		update(t, g, 1)
+		if g.currentMode != before {
+			changes++
+		}
+	}
+	in.release(key)
+	update(t, g, 1)
+	return changes
+}
+
+func TestHeldArrowRepeatsModes(t *testing.T) {
+	g, in := testGame(t)
+	beforeRepeat := int(repeatDelay/tickLength()) - 1
+	if got := holdCount(t, g, in, ebiten.KeyRight, beforeRepeat); got != 1 {
+		t.Errorf("holding right short of the repeat delay changed mode %d times, want 1", got)
+	}
+
+	// A second of holding: the press, then once at the repeat delay and every
+	// menuInterval after that
+	second := int(time.Second / tickLength())
+	want := 2 + int((time.Second-repeatDelay)/menuInterval)
+	if got := holdCount(t, g, in, ebiten.KeyLeft, second); got < want-1 || got > want {
+		t.Errorf("holding left for a second changed mode %d times, want about %d", got, want)
+	}
+}
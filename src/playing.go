@@ -0,0 +1,453 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+)
+
+// visibleRows is how many nodes fit in the listing below the header, leaving
+// room at the bottom for the console when it's open
+func (g *Game) visibleRows() int {
+	if g.commandActive {
+		return max(1, g.rowsFrom(2)-consoleVisibleLines-1)
+	}
+	return g.rowsFrom(2)
+}
+
+func (g *Game) updatePlaying() {
+	// Undo works from the listing and the console alike
+	if g.undoPressed() {
+		g.undoDelete()
+		return
+	}
+	if g.commandActive {
+		g.updateConsole()
+		return
+	}
+	if g.searchActive {
+		g.updateSearch()
+		return
+	}
+
+	// The console key (TAB by default) opens the command console
+	if g.justPressed(ActionConsole) {
+		g.commandActive = true
+		g.setInput("")
+		g.historyIndex = len(g.commandHistory)
+		g.moveSelection(0) // the listing just got shorter
+		return
+	}
+	if g.justPressed(ActionSearch) {
+		g.searchActive = true
+		g.setInput(g.filter)
+		return
+	}
+	// Escape clears a leftover filter before it pauses
+	if g.filter != "" && g.justPressed(ActionCancel) {
+		g.setFilter("")
+		return
+	}
+	if g.justPressed(ActionPause) {
+		g.pause()
+		return
+	}
+	if g.justPressed(ActionMoveDown) {
+		g.moveSelection(1)
+	}
+	if g.justPressed(ActionMoveUp) {
+		g.moveSelection(-1)
+	}
+	// Page keys jump a screenful, moveSelection drags the window along
+	if g.justPressed(ActionPageDown) {
+		g.moveSelection(g.visibleRows())
+	}
+	if g.justPressed(ActionPageUp) {
+		g.moveSelection(-g.visibleRows())
+	}
+	if g.justPressed(ActionSecure) {
+		g.secureSelected()
+	}
+	if g.justPressed(ActionYank) {
+		g.yankSelected()
+	}
+	if g.justPressed(ActionSort) {
+		g.cycleSort()
+	}
+	// Enter drills into a directory, backspace climbs back out
+	if g.justPressed(ActionConfirm) {
+		g.enterSelected()
+	}
+	if g.justPressed(ActionDelete) {
+		g.leaveDir()
+	}
+}
+
+// yankSelected copies the absolute path of the highlighted node to the
+// system clipboard, for using it outside the game
+func (g *Game) yankSelected() {
+	node, ok := g.highlighted()
+	if !ok {
+		return
+	}
+	path := node.Path
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if g.clipboard.Write(path) {
+		g.pushToast(tr("playing.copied"), toastTime)
+	}
+}
+
+// highlighted is a copy of the node under the cursor, ok is false when the
+// listing is empty
+func (g *Game) highlighted() (FSNode, bool) {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	view := g.viewNodes()
+	if g.selectedNode >= len(view) {
+		return FSNode{}, false
+	}
+	return g.fsNodes[view[g.selectedNode]], true
+}
+
+// secureSelected locks down the highlighted node, which is how FLAGs get captured
+func (g *Game) secureSelected() {
+	secured, drained := g.secureHighlighted()
+	if secured {
+		g.scoreAction()
+	}
+	if drained {
+		g.pushToast(tr("toast.no_energy"), toastTime)
+	}
+}
+
+// secureHighlighted marks the node under the cursor secured if there's the
+// energy for it. drained is true when there wasn't.
+func (g *Game) secureHighlighted() (secured, drained bool) {
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	view := g.viewNodes()
+	if g.selectedNode >= len(view) || g.fsNodes[view[g.selectedNode]].Secured {
+		return false, false
+	}
+	if !g.spend(secureCost) {
+		return false, true
+	}
+	g.fsNodes[view[g.selectedNode]].Secured = true
+	return true, false
+}
+
+// checkWin reports whether the current run has been won. Every mode is won the
+// same way, by securing all the FLAG nodes; the harder modes just give you more
+// ways to lose first. A target with no FLAGs can't be won, only abandoned.
+func (g *Game) checkWin() bool {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+
+	flags := 0
+	for _, node := range g.fsNodes {
+		if !node.Flag {
+			continue
+		}
+		if !node.Secured {
+			return false
+		}
+		flags++
+	}
+	return flags > 0
+}
+
+// checkLose reports whether the current run has been lost. In DANGER mode
+// securing a dummy trips the alarm and ends the run, and so does running out
+// of time.
+func (g *Game) checkLose() bool {
+	if g.currentMode != ModeDanger {
+		return false
+	}
+	if dangerTimeRemaining(g.runElapsed()) <= 0 {
+		return true
+	}
+
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	for _, node := range g.fsNodes {
+		if node.Dummy && node.Secured {
+			return true
+		}
+	}
+	return false
+}
+
+// How long a DANGER run lasts, and when the countdown starts to panic
+const (
+	dangerTimeLimit = 120 * time.Second
+	dangerCritical  = 10 * time.Second
+)
+
+// dangerTimeRemaining is what's left on the DANGER countdown after elapsed
+// (unpaused) play time, never below zero
+func dangerTimeRemaining(elapsed time.Duration) time.Duration {
+	return max(0, dangerTimeLimit-elapsed)
+}
+
+// startPlaying resets the listing once a scan finishes
+func (g *Game) startPlaying() {
+	g.selectedNode = 0
+	g.listOffset = 0
+	g.searchActive = false
+	g.filter = ""
+	g.dirStack = nil
+	g.score = 0
+	g.combo = 0
+	g.energy = maxEnergy
+	g.runStart = g.now()
+	g.pausedTotal = 0
+	g.cwd = g.finalFilesystemPath
+	g.commandActive = false
+	g.commandOutput = nil
+	g.undoStack = nil
+	g.previewCache = nil
+	g.rng = rand.New(rand.NewSource(g.seed))
+	g.glitch = newGlitchState(g.rng.Int63())
+	if g.resuming != nil {
+		g.restoreSnapshot(g.resuming)
+		g.resuming = nil
+	}
+	g.state = StatePlaying
+}
+
+// pause freezes the run, timers stop counting until resume
+func (g *Game) pause() {
+	g.pausedAt = g.now()
+	g.state = StatePaused
+}
+
+// abandonRun gives up on the run from the pause screen, counted as a loss.
+// It's the only way out of a target that can't be won.
+func (g *Game) abandonRun() {
+	g.finishRun(false)
+	g.returnToMenu()
+}
+
+func (g *Game) resume() {
+	g.pausedTotal += g.since(g.pausedAt)
+	g.state = StatePlaying
+}
+
+// runElapsed is how long the current run has been played, not counting pauses
+func (g *Game) runElapsed() time.Duration {
+	paused := g.pausedTotal
+	if g.state == StatePaused {
+		paused += g.since(g.pausedAt)
+	}
+	return g.since(g.runStart) - paused
+}
+
+// viewCount is how many nodes the listing currently shows
+func (g *Game) viewCount() int {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+	return len(g.viewNodes())
+}
+
+// moveSelection shifts the cursor by delta, clamped to the filtered view, and
+// scrolls the visible window so the cursor never leaves it
+func (g *Game) moveSelection(delta int) {
+	count := g.viewCount()
+	if count == 0 {
+		g.selectedNode = 0
+		g.listOffset = 0
+		return
+	}
+
+	g.selectedNode = max(0, min(g.selectedNode+delta, count-1))
+	if g.selectedNode < g.listOffset {
+		g.listOffset = g.selectedNode
+	} else if g.selectedNode >= g.listOffset+g.visibleRows() {
+		g.listOffset = g.selectedNode - g.visibleRows() + 1
+	}
+	// Don't leave empty rows at the bottom once the list shrinks or the window grows
+	g.listOffset = max(0, min(g.listOffset, count-g.visibleRows()))
+}
+
+func (g *Game) drawPlaying(screen *ebiten.Image) {
+	g.fsMutex.RLock()
+	defer g.fsMutex.RUnlock()
+
+	if len(g.fsNodes) == 0 {
+		text.Draw(screen, tr("playing.no_nodes"), mplusNormalFont, g.marginX(), g.lineY(0), g.theme().Foreground)
+		return
+	}
+
+	g.drawSummary(screen)
+	// The demo isn't a real run, so it goes without the mode's timer or meter
+	if g.state != StateAttract {
+		switch g.currentMode {
+		case ModeDanger:
+			g.drawCountdown(screen)
+		case ModeDestruction:
+			g.drawScore(screen)
+			g.drawEnergy(screen)
+		}
+	}
+
+	view := g.viewNodes()
+	if g.selectedNode < len(view) {
+		text.Draw(screen, "> "+g.fsNodes[view[g.selectedNode]].Path, mplusNormalFont, g.marginX(), g.lineY(0), g.terminalColor)
+	} else {
+		drawBold(screen, g.warn(tr("playing.no_matches")), mplusNormalFont, g.marginX(), g.lineY(0), g.theme().Warning)
+	}
+
+	// The row under the header holds the search prompt and where we are in the list
+	end := min(g.listOffset+g.visibleRows(), len(view))
+	x := g.marginX() * 2
+	if dir := g.browseDir(); dir != "" {
+		in := fmt.Sprintf(tr("playing.in_dir"), dir) + "  "
+		text.Draw(screen, in, mplusNormalFont, x, g.lineY(1), g.theme().Dim)
+		x += font.MeasureString(mplusNormalFont, in).Ceil()
+	}
+	if g.searchActive {
+		g.drawPrompt(screen, "/", x, g.lineY(1), 0, g.theme().Dim)
+	} else if g.filter != "" {
+		text.Draw(screen, "/"+g.filter, mplusNormalFont, x, g.lineY(1), g.theme().Dim)
+	}
+	if g.searchActive || g.filter != "" {
+		x += font.MeasureString(mplusNormalFont, "/"+g.filter+"_  ").Ceil()
+	}
+	if g.listSort != sortScan {
+		by := fmt.Sprintf(tr("playing.sort"), listSortNames[g.listSort]) + "  "
+		text.Draw(screen, by, mplusNormalFont, x, g.lineY(1), g.theme().Dim)
+		x += font.MeasureString(mplusNormalFont, by).Ceil()
+	}
+	if g.listOffset > 0 || end < len(view) {
+		pos := fmt.Sprintf(tr("playing.page"), g.listOffset+1, end, len(view))
+		text.Draw(screen, pos, mplusNormalFont, x, g.lineY(1), g.theme().Dim)
+	}
+
+	var selected *FSNode
+	if g.selectedNode < len(view) {
+		selected = &g.fsNodes[view[g.selectedNode]]
+	}
+	g.drawSidebar(screen, selected)
+
+	listX := g.marginX()*2 + g.sidebarWidth()
+	for i := g.listOffset; i < end; i++ {
+		node := g.fsNodes[view[i]]
+		displayColor := g.theme().Dim
+		prefix := "  "
+		if i == g.selectedNode {
+			displayColor = g.terminalColor
+			prefix = "> "
+		}
+		// A one character column in front says what kind of node it is
+		marker := node.marker()
+		if marker == "" {
+			marker = " "
+		}
+		name := marker + " " + node.Path
+		// Dummies look exactly like the real thing
+		if node.Flag || node.Dummy {
+			name += " [FLAG]"
+		}
+		if node.Secured {
+			name += " [SECURED]"
+		}
+		if node.Loop {
+			name += " [LOOP]"
+		}
+		line := prefix + name
+		if g.glitchEnabled() {
+			line = g.glitch.flicker(line, i)
+		}
+		text.Draw(screen, line, mplusNormalFont, listX, g.lineY(2+i-g.listOffset), displayColor)
+	}
+	g.drawPreview(screen, selected)
+}
+
+// drawTitle draws a heading in the big face across the first two text rows
+func (g *Game) drawTitle(screen *ebiten.Image, title string, clr color.Color) {
+	face := getFace(titleFontSize)
+	text.Draw(screen, title, face, g.marginX(), g.marginY()+face.Metrics().Ascent.Ceil(), clr)
+}
+
+func (g *Game) drawWon(screen *ebiten.Image) {
+	g.drawTitle(screen, tr("won.title"), g.theme().Foreground)
+	text.Draw(screen, fmt.Sprintf(tr("won.message"), modeNames[g.currentMode]), mplusNormalFont, g.marginX(), g.lineY(2), g.theme().Foreground)
+	row := 3
+	if g.currentMode == ModeDestruction {
+		score := fmt.Sprintf(tr("won.score"), g.score)
+		if g.newBestScore {
+			score += "  " + tr("won.new_best")
+		}
+		text.Draw(screen, score, mplusNormalFont, g.marginX(), g.lineY(row), g.theme().Foreground)
+		row++
+	}
+	text.Draw(screen, tr("end.return"), mplusNormalFont, g.marginX(), g.lineY(row), g.theme().Foreground)
+}
+
+func (g *Game) drawLoose(screen *ebiten.Image) {
+	msg := tr("lost.alarm")
+	if g.currentMode == ModeDanger && dangerTimeRemaining(g.runDuration) <= 0 {
+		msg = tr("lost.timeout")
+	}
+	g.drawTitle(screen, tr("lost.title"), g.theme().Warning)
+	drawBold(screen, g.warn(msg), mplusNormalFont, g.marginX(), g.lineY(2), g.theme().Warning)
+	text.Draw(screen, tr("end.return"), mplusNormalFont, g.marginX(), g.lineY(3), g.theme().Warning)
+}
+
+func (g *Game) drawPaused(screen *ebiten.Image) {
+	// Dim whatever is underneath
+	vector.FillRect(screen, 0, 0, float32(g.screenWidth), float32(g.screenHeight), color.RGBA{0, 0, 0, 180}, false)
+	msg := tr("paused.message")
+	x := (g.screenWidth - font.MeasureString(mplusNormalFont, msg).Ceil()) / 2
+	text.Draw(screen, msg, mplusNormalFont, x, g.screenHeight/2, g.theme().Foreground)
+	abandon := tr("paused.abandon")
+	x = (g.screenWidth - font.MeasureString(mplusNormalFont, abandon).Ceil()) / 2
+	text.Draw(screen, abandon, mplusNormalFont, x, g.screenHeight/2+lineHeight(), g.theme().Dim)
+}
+
+// drawSummary shows totals for the target in the top right corner.
+// Caller must hold fsMutex.
+func (g *Game) drawSummary(screen *ebiten.Image) {
+	lines := []string{
+		fmt.Sprintf(tr("playing.nodes"), g.fsStats.Files+g.fsStats.Dirs),
+		fmt.Sprintf(tr("playing.size"), humanizeBytes(g.fsStats.Bytes)),
+		fmt.Sprintf(tr("playing.files_dirs"), g.fsStats.Files, g.fsStats.Dirs),
+		fmt.Sprintf(tr("playing.seed"), g.seed),
+	}
+	if g.fsTruncated {
+		lines = append(lines, fmt.Sprintf(tr("scan.truncated"), g.fsNodeCount))
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(mplusNormalFont, line).Ceil())
+	}
+	x := g.screenWidth - g.marginX() - width
+	for i, line := range lines {
+		text.Draw(screen, line, mplusNormalFont, x, g.lineY(i), g.theme().Dim)
+	}
+}
+
+// drawCountdown shows the DANGER timer big and centered at the top, turning
+// red for the last few seconds
+func (g *Game) drawCountdown(screen *ebiten.Image) {
+	remaining := dangerTimeRemaining(g.runElapsed())
+	secs := int(remaining.Seconds() + 0.999) // round up so it only reads 00:00 at zero
+	str := fmt.Sprintf(tr("playing.countdown"), secs/60, secs%60)
+	clr := g.theme().Foreground
+	if remaining <= dangerCritical {
+		clr = g.theme().Warning
+		str = g.warn(str)
+	}
+	x := (g.screenWidth - font.MeasureString(mplusNormalFont, str).Ceil()) / 2
+	text.Draw(screen, str, mplusNormalFont, x, g.lineY(0), clr)
+}
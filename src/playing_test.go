@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// startRun scans root in fsys and starts playing it in mode, the way
+// finishing the scan screen would
+func startRun(t *testing.T, g *Game, mode Mode, fsys scanFS, root string) {
+	t.Helper()
+	g.currentMode = mode
+	g.finalFilesystemPath = root
+	scan(t, g, fsys, root)
+	if !g.fsReady {
+		t.Fatalf("scan of %s failed: %v", root, g.fsErr)
+	}
+	g.startPlaying()
+}
+
+func TestSelectionMovesAndClamps(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	count := len(g.fsNodes)
+
+	tap(t, g, in, ebiten.KeyDown)
+	tap(t, g, in, ebiten.KeyDown)
+	if g.selectedNode != 2 {
+		t.Fatalf("two downs selected %d, want 2", g.selectedNode)
+	}
+	tap(t, g, in, ebiten.KeyUp)
+	if g.selectedNode != 1 {
+		t.Fatalf("up selected %d, want 1", g.selectedNode)
+	}
+
+	// Past either end the cursor stays on the first or last node
+	for range 3 {
+		tap(t, g, in, ebiten.KeyUp)
+	}
+	if g.selectedNode != 0 {
+		t.Fatalf("selection went to %d above the top", g.selectedNode)
+	}
+	g.moveSelection(count + 10)
+	if g.selectedNode != count-1 {
+		t.Fatalf("selection went to %d past the bottom, want %d", g.selectedNode, count-1)
+	}
+}
+
+func TestAbandonUnwinnableRun(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, mustMemFS("/empty/"), "/empty")
+	update(t, g, 1)
+	if g.checkWin() {
+		t.Fatal("a target with no FLAGs was won")
+	}
+
+	tap(t, g, in, ebiten.KeyEscape)
+	if g.state != StatePaused {
+		t.Fatalf("escape went to %v, want paused", g.state)
+	}
+	tap(t, g, in, ebiten.KeyQ)
+	if g.state != StateMenu {
+		t.Fatalf("abandoning went to %v, want the menu", g.state)
+	}
+	if g.save.LastMode != "SAFE" || g.save.Modes["SAFE"] != nil {
+		t.Fatalf("abandoned run wasn't recorded as a loss: %+v", g.save)
+	}
+}
+
+// nodeWhere is the index of the first node matching pred
+func nodeWhere(t *testing.T, g *Game, pred func(FSNode) bool) int {
+	t.Helper()
+	for i, node := range g.fsNodes {
+		if pred(node) {
+			return i
+		}
+	}
+	t.Fatal("no node matches")
+	return -1
+}
+
+func TestCheckLose(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		dummy bool          // secure a dummy
+		wait  time.Duration // play time that goes by
+		lost  bool
+	}{
+		{"fresh danger run", ModeDanger, false, 0, false},
+		{"danger dummy secured", ModeDanger, true, 0, true},
+		{"danger out of time", ModeDanger, false, dangerTimeLimit, true},
+		{"danger with time left", ModeDanger, false, dangerTimeLimit - time.Second, false},
+		{"safe dummy secured", ModeSafe, true, 0, false},
+		{"destruction past the danger limit", ModeDestruction, false, dangerTimeLimit, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := testGame(t)
+			startRun(t, g, tt.mode, attractFS, attractRoot)
+			if tt.dummy {
+				g.fsNodes[nodeWhere(t, g, func(n FSNode) bool { return n.Dummy })].Secured = true
+			}
+			g.elapsed += tt.wait
+			if got := g.checkLose(); got != tt.lost {
+				t.Fatalf("checkLose = %v, want %v", got, tt.lost)
+			}
+		})
+	}
+}
+
+func TestPauseStopsTimers(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeDanger, attractFS, attractRoot)
+	second := int(time.Second / tickLength())
+
+	update(t, g, 10*second)
+	before := g.runElapsed()
+	tap(t, g, in, ebiten.KeyEscape)
+	update(t, g, 30*second)
+	if g.runElapsed() != before+tickLength() {
+		t.Fatalf("run timer moved from %v to %v while paused", before, g.runElapsed())
+	}
+	tap(t, g, in, ebiten.KeyEscape)
+	if g.state != StatePlaying {
+		t.Fatalf("escape went to %v, want playing", g.state)
+	}
+	update(t, g, second)
+
+	// ten seconds before, one after, and the frame that paused
+	want := 11*time.Second + tickLength()
+	if got := g.runElapsed(); got.Round(time.Millisecond) != want.Round(time.Millisecond) {
+		t.Fatalf("run timer reads %v, want %v with the pause left out", got, want)
+	}
+}
+
+func TestDangerTimeRemaining(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{0, dangerTimeLimit},
+		{30 * time.Second, 90 * time.Second},
+		{dangerTimeLimit - time.Millisecond, time.Millisecond},
+		{dangerTimeLimit, 0},
+		{dangerTimeLimit + time.Hour, 0},
+	}
+	for _, tt := range tests {
+		if got := dangerTimeRemaining(tt.elapsed); got != tt.want {
+			t.Errorf("dangerTimeRemaining(%v) = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestDangerCountdownLoses(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeDanger, attractFS, attractRoot)
+	ticks := int(dangerTimeLimit / tickLength())
+
+	update(t, g, ticks-2)
+	if g.state != StatePlaying {
+		t.Fatalf("state %v with time left on the clock", g.state)
+	}
+	update(t, g, 3)
+	if g.state != StateLoose {
+		t.Fatalf("state %v after the countdown ran out, want lost", g.state)
+	}
+}
+
+func TestScrollFollowsSelection(t *testing.T) {
+	files := map[string]string{}
+	for i := range 100 {
+		files[fmt.Sprintf("f%03d.txt", i)] = "x"
+	}
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, diskFS{}, writeTree(t, files))
+	count := len(g.viewNodes())
+	rows := g.visibleRows()
+	if rows >= count {
+		t.Fatalf("%d rows fit all %d nodes, nothing to scroll", rows, count)
+	}
+
+	// Walking off the bottom drags the window along one row at a time
+	g.moveSelection(rows - 1)
+	if g.listOffset != 0 {
+		t.Fatalf("offset %d with the cursor still on screen", g.listOffset)
+	}
+	tap(t, g, in, ebiten.KeyDown)
+	if g.selectedNode != rows || g.listOffset != 1 {
+		t.Fatalf("selected %d offset %d, want %d and 1", g.selectedNode, g.listOffset, rows)
+	}
+
+	tap(t, g, in, ebiten.KeyPageDown)
+	if g.selectedNode != 2*rows || g.listOffset != rows+1 {
+		t.Fatalf("page down: selected %d offset %d, want %d and %d", g.selectedNode, g.listOffset, 2*rows, rows+1)
+	}
+	update(t, g, 5)
+	tap(t, g, in, ebiten.KeyPageUp)
+	if g.selectedNode != rows || g.listOffset != rows {
+		t.Fatalf("page up: selected %d offset %d, want %d twice", g.selectedNode, g.listOffset, rows)
+	}
+
+	// Both ends clamp, and the bottom doesn't leave empty rows below the list
+	g.moveSelection(10 * count)
+	if g.selectedNode != count-1 || g.listOffset != count-rows {
+		t.Fatalf("past the end: selected %d offset %d, want %d and %d", g.selectedNode, g.listOffset, count-1, count-rows)
+	}
+	g.moveSelection(-10 * count)
+	if g.selectedNode != 0 || g.listOffset != 0 {
+		t.Fatalf("past the start: selected %d offset %d, want 0 and 0", g.selectedNode, g.listOffset)
+	}
+
+	// A taller window pulls the offset back so the list still fills it
+	g.moveSelection(count)
+	g.screenHeight *= 10
+	g.moveSelection(0)
+	if g.listOffset != 0 {
+		t.Errorf("offset %d once everything fits", g.listOffset)
+	}
+}
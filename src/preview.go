@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// SAFE mode shows the head of the highlighted file in a pane on the right.
+// Files are only ever opened for reading, and only previewMaxBytes of them.
+const (
+	previewMaxBytes = 4096
+	previewFraction = 0.3
+	previewMinWidth = 240
+)
+
+// filePreview is what the pane shows for one file
+type filePreview struct {
+	lines  []string
+	binary bool
+	err    error
+}
+
+// looksBinary sniffs the start of a file: a NUL byte, invalid UTF-8 or a lot
+// of control characters means it isn't text. A rune cut off at the end of
+// the sample doesn't count against it.
+func looksBinary(b []byte) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return true
+	}
+	for i := 0; i < utf8.UTFMax && len(b) > 0 && !utf8.Valid(b); i++ {
+		b = b[:len(b)-1]
+	}
+	if !utf8.Valid(b) {
+		return true
+	}
+	control := 0
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' {
+			control++
+		}
+	}
+	return control*10 > len(b)
+}
+
+// readHead reads at most max bytes from the start of path
+func readHead(path string, max int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, int64(max)))
+}
+
+// loadPreview reads and splits up the head of path for the pane
+func loadPreview(path string) filePreview {
+	head, err := readHead(path, previewMaxBytes)
+	if err != nil {
+		return filePreview{err: err}
+	}
+	if looksBinary(head) {
+		return filePreview{binary: true}
+	}
+	s := strings.ReplaceAll(strings.ToValidUTF8(string(head), ""), "\r", "")
+	s = strings.ReplaceAll(s, "\t", "    ")
+	return filePreview{lines: strings.Split(strings.TrimRight(s, "\n"), "\n")}
+}
+
+// preview is loadPreview for path, read the first time it's asked for and
+// cached for the rest of the run
+func (g *Game) preview(path string) filePreview {
+	if p, ok := g.previewCache[path]; ok {
+		return p
+	}
+	if g.previewCache == nil {
+		g.previewCache = map[string]filePreview{}
+	}
+	p := loadPreview(path)
+	g.previewCache[path] = p
+	return p
+}
+
+// previewWidth is how much room the pane takes on the right, 0 when it's
+// hidden: outside SAFE or when the window is too narrow
+func (g *Game) previewWidth() int {
+	w := int(float64(g.screenWidth) * previewFraction)
+	if g.currentMode != ModeSafe || w < previewMinWidth {
+		return 0
+	}
+	return w
+}
+
+// drawPreview draws the pane for the selected node over the right side of
+// the listing. Only regular files get one, opening a pipe would block, and
+// never the demo's, which aren't on disk.
+func (g *Game) drawPreview(screen *ebiten.Image, selected *FSNode) {
+	width := g.previewWidth()
+	if width == 0 || g.state == StateAttract || selected == nil || selected.IsDir || !selected.Mode.IsRegular() {
+		return
+	}
+
+	x := g.screenWidth - width
+	top := g.lineY(2) - mplusNormalFont.Metrics().Ascent.Ceil()
+	vector.FillRect(screen, float32(x), float32(top), float32(width), float32(g.screenHeight-top), g.theme().Background, false)
+	vector.FillRect(screen, float32(x), float32(top), 1, float32(g.screenHeight-top), g.theme().Dim, false)
+
+	x += g.marginX()
+	inner := width - 2*g.marginX()
+	p := g.preview(selected.Path)
+	switch {
+	case p.err != nil:
+		text.Draw(screen, fitText(tr("preview.unreadable"), inner), mplusNormalFont, x, g.lineY(2), g.theme().Dim)
+	case p.binary:
+		text.Draw(screen, fitText(tr("preview.binary"), inner), mplusNormalFont, x, g.lineY(2), g.theme().Dim)
+	default:
+		rows := g.visibleRows()
+		for i, line := range p.lines[:min(len(p.lines), rows)] {
+			text.Draw(screen, fitText(line, inner), mplusNormalFont, x, g.lineY(2+i), g.theme().Dim)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLooksBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"text", []byte("hello\nworld\n"), false},
+		{"tabs and crlf", []byte("a\tb\r\nc\f"), false},
+		{"utf-8", []byte("héllo wörld ✓"), false},
+		{"cut off rune", []byte("ok ✓")[:5], false},
+		{"nul", []byte("abc\x00def"), true},
+		{"invalid utf-8", []byte("abc\xff\xfe def"), true},
+		{"control chars", []byte("\x01\x02\x03\x04abcdef"), true},
+		{"png", []byte("\x89PNG\r\n\x1a\n"), true},
+	}
+	for _, tt := range tests {
+		if got := looksBinary(tt.data); got != tt.want {
+			t.Errorf("%s: looksBinary = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPreviewByteCap(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	data := strings.Repeat(line, 100) // 10000 bytes
+	path := filepath.Join(t.TempDir(), "big.txt")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := readHead(path, previewMaxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head) != previewMaxBytes || !bytes.Equal(head, []byte(data[:previewMaxBytes])) {
+		t.Errorf("read %d bytes, want the first %d", len(head), previewMaxBytes)
+	}
+	p := loadPreview(path)
+	if p.err != nil || p.binary {
+		t.Fatalf("preview err %v binary %v", p.err, p.binary)
+	}
+	if want := previewMaxBytes/len(line) + 1; len(p.lines) != want {
+		t.Errorf("preview has %d lines, want %d from the capped head", len(p.lines), want)
+	}
+
+	if after, err := os.ReadFile(path); err != nil || string(after) != data {
+		t.Errorf("previewing changed the file: %v", err)
+	}
+}
+
+func TestPreviewIsCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := testGame(t)
+	if got := g.preview(path).lines; len(got) != 1 || got[0] != "first" {
+		t.Fatalf("preview %q", got)
+	}
+	if err := os.WriteFile(path, []byte("second"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.preview(path).lines; got[0] != "first" {
+		t.Errorf("second preview read the file again, got %q", got)
+	}
+}
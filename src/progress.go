@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+)
+
+// The font has no block glyphs, so the scan progress bar is drawn as solid
+// character cells instead
+const indeterminateCells = 4 // width of the block that sweeps back and forth
+
+// progressFill is how many of cells should be filled with count of total
+// nodes scanned, or -1 for an indeterminate bar when total isn't known (0).
+func progressFill(count, total, cells int) int {
+	if total <= 0 {
+		return -1
+	}
+	return min(cells, max(0, count*cells/total))
+}
+
+// scanEstimate is the total the scan's progress bar fills towards. Nothing
+// knows how big the target is until the walk is over, so the best guess is
+// the node limit, and without one the bar is indeterminate.
+func (g *Game) scanEstimate() int {
+	return max(0, g.scanLimits.MaxNodes)
+}
+
+// drawProgressBar draws a row of cells on the text row whose baseline is y,
+// as wide as the text area. Filled cells are solid, the rest are dim.
+func (g *Game) drawProgressBar(screen *ebiten.Image, x, y, count, total int) {
+	cellW := font.MeasureString(mplusNormalFont, "#").Ceil()
+	cells := max(1, g.textWidth()/cellW)
+	ascent := mplusNormalFont.Metrics().Ascent.Ceil()
+
+	// Indeterminate sweeps a short block across, timed off the clock like the spinner
+	from, to := 0, progressFill(count, total, cells)
+	if to < 0 {
+		span := cells - indeterminateCells
+		step := int(g.now().UnixMilli()/60) % max(1, 2*span)
+		if step > span {
+			step = 2*span - step
+		}
+		from, to = step, step+indeterminateCells
+	}
+
+	theme := g.theme()
+	for i := 0; i < cells; i++ {
+		clr := theme.Glow
+		if i >= from && i < to {
+			clr = g.terminalColor
+		}
+		vector.FillRect(screen, float32(x+i*cellW), float32(y-ascent), float32(cellW-1), float32(ascent), clr, false)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestProgressFill(t *testing.T) {
+	tests := []struct {
+		count, total, cells int
+		want                int
+	}{
+		{0, 0, 40, -1}, // unknown total
+		{500, 0, 40, -1},
+		{10, -1, 40, -1},
+		{0, 100, 40, 0},
+		{50, 100, 40, 20},
+		{99, 100, 40, 39}, // only full once it's all there
+		{100, 100, 40, 40},
+		{150, 100, 40, 40},
+		{1, 3, 10, 3},
+	}
+	for _, tt := range tests {
+		if got := progressFill(tt.count, tt.total, tt.cells); got != tt.want {
+			t.Errorf("progressFill(%d, %d, %d) = %d, want %d", tt.count, tt.total, tt.cells, got, tt.want)
+		}
+	}
+}
+
+func TestScanEstimate(t *testing.T) {
+	g, _ := testGame(t)
+	g.scanLimits = scanLimits{MaxNodes: 200}
+	if got := progressFill(50, g.scanEstimate(), 40); got != 10 {
+		t.Errorf("50 of a 200 node limit filled %d of 40 cells, want 10", got)
+	}
+	g.scanLimits = scanLimits{}
+	if got := progressFill(50, g.scanEstimate(), 40); got != -1 {
+		t.Errorf("without a node limit the bar filled %d cells, want indeterminate", got)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// inputEvent is one line of a recording: a key going down or up, some typed
+// text or a click, on a given Update frame
+type inputEvent struct {
+	Frame int          `json:"frame"`
+	Key   *ebiten.Key  `json:"key,omitempty"`
+	Down  bool         `json:"down,omitempty"`
+	Chars string       `json:"chars,omitempty"`
+	Click *image.Point `json:"click,omitempty"`
+}
+
+// replayHeader is the first line of a recording. The run's randomness comes
+// from the seed, so a replay has to use the one the session was played with.
+type replayHeader struct {
+	Seed int64 `json:"seed"`
+}
+
+// recordInput passes another InputSource through while writing every change
+// to w, the header and then one JSON event per line
+type recordInput struct {
+	InputSource
+	w      *bufio.Writer
+	enc    *json.Encoder
+	closer io.Closer // the file behind w, if there is one
+	frame  int
+	held   map[ebiten.Key]bool
+}
+
+func newRecordInput(src InputSource, w io.Writer, seed int64) *recordInput {
+	bw := bufio.NewWriter(w)
+	r := &recordInput{InputSource: src, w: bw, enc: json.NewEncoder(bw), held: map[ebiten.Key]bool{}}
+	r.enc.Encode(replayHeader{seed})
+	return r
+}
+
+// recordToFile records the live keyboard into a new file at path
+func recordToFile(path string, seed int64) (*recordInput, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := newRecordInput(ebitenInput{}, f, seed)
+	r.closer = f
+	return r, nil
+}
+
+// Tick ticks the source and writes down what changed. Releases are spotted
+// by checking every key held last frame, since an InputSource only says
+// what's down.
+func (r *recordInput) Tick() {
+	r.InputSource.Tick()
+	r.frame++
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		switch {
+		case r.JustPressed(key):
+			r.enc.Encode(inputEvent{Frame: r.frame, Key: &key, Down: true})
+			r.held[key] = true
+		case r.held[key] && !r.Pressed(key):
+			r.enc.Encode(inputEvent{Frame: r.frame, Key: &key})
+			delete(r.held, key)
+		}
+	}
+	if chars := r.Chars(); len(chars) > 0 {
+		r.enc.Encode(inputEvent{Frame: r.frame, Chars: string(chars)})
+	}
+	if pt, ok := r.Click(); ok {
+		r.enc.Encode(inputEvent{Frame: r.frame, Click: &pt})
+	}
+}
+
+// Close flushes the recording, call it once RunGame returns
+func (r *recordInput) Close() error {
+	err := r.w.Flush()
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+	}
+	return err
+}
+
+// replayInput plays a recording back frame by frame, then hands control
+// back to the live keyboard once it runs out. Timers run on the game clock,
+// which also counts frames, so they line up with the recording too.
+type replayInput struct {
+	seed    int64 // from the header, the game has to be seeded with it
+	live    InputSource
+	events  []inputEvent
+	next    int
+	frame   int
+	pressed map[ebiten.Key]bool
+	just    map[ebiten.Key]bool
+	chars   []rune
+	click   *image.Point
+}
+
+func loadReplay(path string) (*replayInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readReplay(f, ebitenInput{})
+}
+
+// readReplay reads a recording, live takes over once it's played out
+func readReplay(rd io.Reader, live InputSource) (*replayInput, error) {
+	dec := json.NewDecoder(rd)
+	var header replayHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("reading the header: %w", err)
+	}
+	if header.Seed == 0 {
+		return nil, errors.New("the header has no seed")
+	}
+
+	r := &replayInput{seed: header.Seed, live: live, pressed: map[ebiten.Key]bool{}, just: map[ebiten.Key]bool{}}
+	for {
+		var e inputEvent
+		if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		r.events = append(r.events, e)
+	}
+	return r, nil
+}
+
+// finished reports whether the recording has been used up
+func (r *replayInput) finished() bool {
+	return r.next >= len(r.events)
+}
+
+func (r *replayInput) Tick() {
+	r.live.Tick()
+	r.frame++
+	clear(r.just)
+	r.chars = r.chars[:0]
+	r.click = nil
+	for ; !r.finished() && r.events[r.next].Frame <= r.frame; r.next++ {
+		e := r.events[r.next]
+		if e.Key != nil {
+			r.pressed[*e.Key] = e.Down
+			r.just[*e.Key] = e.Down
+		}
+		r.chars = append(r.chars, []rune(e.Chars)...)
+		if e.Click != nil {
+			r.click = e.Click
+		}
+	}
+}
+
+func (r *replayInput) JustPressed(key ebiten.Key) bool {
+	if r.finished() && len(r.just) == 0 {
+		return r.live.JustPressed(key)
+	}
+	return r.just[key]
+}
+
+func (r *replayInput) Pressed(key ebiten.Key) bool {
+	if r.finished() && len(r.just) == 0 {
+		return r.live.Pressed(key)
+	}
+	return r.pressed[key]
+}
+
+func (r *replayInput) AnyJustPressed() bool {
+	if r.finished() && len(r.just) == 0 {
+		return r.live.AnyJustPressed()
+	}
+	for _, down := range r.just {
+		if down {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *replayInput) Chars() []rune {
+	if r.finished() && len(r.just) == 0 && len(r.chars) == 0 {
+		return r.live.Chars()
+	}
+	return r.chars
+}
+
+func (r *replayInput) Click() (image.Point, bool) {
+	if r.finished() && len(r.just) == 0 && r.click == nil {
+		return r.live.Click()
+	}
+	if r.click == nil {
+		return image.Point{}, false
+	}
+	return *r.click, true
+}
+
+// Cursor always follows the live mouse, it only ever moves a highlight
+func (r *replayInput) Cursor() image.Point {
+	return r.live.Cursor()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// replayGame is a game booting after a DANGER run, whose boot is shuffled by
+// the seed, reading from input
+func replayGame(t *testing.T, input InputSource, seed int64) *Game {
+	t.Helper()
+	save := newSaveData()
+	save.TutorialSeen = true
+	save.LastMode = "DANGER"
+	g := newGame(input, save)
+	g.screenWidth, g.screenHeight = 1920, 1080
+	g.seed = seed
+	g.startBoot()
+	return g
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Setenv(configDirEnv, t.TempDir())
+	in := newFakeInput()
+	var recording bytes.Buffer
+	rec := newRecordInput(in, &recording, 1234)
+	g := replayGame(t, rec, 1234)
+
+	// Boot, pick a dangerous mode, confirm it and try a target that isn't there
+	for i := 0; i < 60*20 && g.state == StateBooting; i++ {
+		update(t, g, 1)
+	}
+	tap(t, g, in, ebiten.KeyRight)
+	tap(t, g, in, ebiten.KeyRight)
+	tap(t, g, in, ebiten.KeyEnter)
+	typeText(t, g, in, "YES")
+	tap(t, g, in, ebiten.KeyEnter)
+	typeText(t, g, in, "/no/such/dir")
+	tap(t, g, in, ebiten.KeyEnter)
+	update(t, g, 5)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if g.state != StateMenu || g.currentMode == ModeSafe || g.inputError == "" {
+		t.Fatalf("the session ended in %s on %s with error %q", g.state, modeNames[g.currentMode], g.inputError)
+	}
+
+	replay, err := readReplay(strings.NewReader(recording.String()), noInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.seed != 1234 {
+		t.Fatalf("the recording kept seed %d", replay.seed)
+	}
+	g2 := replayGame(t, replay, replay.seed)
+	update(t, g2, rec.frame)
+	if !replay.finished() {
+		t.Error("the replay has events left over")
+	}
+	if !slices.Equal(g2.bootLines, g.bootLines) {
+		t.Error("the replay booted differently than the recording")
+	}
+	if g2.state != g.state || g2.currentMode != g.currentMode || g2.inputBuffer != g.inputBuffer || g2.inputError != g.inputError || g2.inputActive != g.inputActive {
+		t.Errorf("replay ended in %s on %s with %q (%q), the recording in %s on %s with %q (%q)",
+			g2.state, modeNames[g2.currentMode], g2.inputBuffer, g2.inputError,
+			g.state, modeNames[g.currentMode], g.inputBuffer, g.inputError)
+	}
+}
+
+func TestReadReplayNeedsHeader(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":     "",
+		"no seed":   `{"frame": 1, "chars": "a"}` + "\n",
+		"bad event": `{"seed": 5}` + "\n" + `{"frame": "one"}` + "\n",
+	} {
+		if _, err := readReplay(strings.NewReader(data), noInput{}); err == nil {
+			t.Errorf("%s: the recording was accepted", name)
+		}
+	}
+}
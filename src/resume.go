@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// RunSnapshot is a run the game was closed in the middle of, enough to start
+// it again on the same target with the clock and score where they were.
+// Secured flags aren't kept, the target gets scanned fresh anyway.
+type RunSnapshot struct {
+	Mode    string        `json:"mode"`
+	Target  string        `json:"target"`
+	Elapsed time.Duration `json:"elapsed"`
+	Score   int           `json:"score"`
+	Seed    int64         `json:"seed"`
+}
+
+// snapshotRun records the run in progress in the save, if there is one
+func (g *Game) snapshotRun() {
+	if g.state != StatePlaying && g.state != StatePaused {
+		return
+	}
+	g.save.Resume = &RunSnapshot{
+		Mode:    modeNames[g.currentMode],
+		Target:  g.finalFilesystemPath,
+		Elapsed: g.runElapsed(),
+		Score:   g.score,
+		Seed:    g.seed,
+	}
+}
+
+// resumeRun picks the saved run back up: same mode and seed, and the target
+// is scanned again before play carries on from the snapshot. A snapshot that
+// no longer makes sense is thrown away.
+func (g *Game) resumeRun() {
+	snap := g.save.Resume
+	mode := slices.Index(modeNames, snap.Mode)
+	path, err := validateTarget(snap.Target, g.save.Settings.FileTargets, g.targetRules)
+	if mode < 0 || err != nil {
+		g.save.Resume = nil
+		g.writeSaveFile()
+		g.pushToast(tr("toast.resume_failed"), toastTime)
+		return
+	}
+	g.currentMode = Mode(mode)
+	g.seed = snap.Seed
+	g.resuming = snap
+	g.engage(path)
+}
+
+// restoreSnapshot puts the clock and score back, right after startPlaying
+// has reset them
+func (g *Game) restoreSnapshot(snap *RunSnapshot) {
+	g.runStart = g.now().Add(-snap.Elapsed)
+	g.score = snap.Score
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// playUntil updates until the game reaches state, failing after ten seconds
+func playUntil(t *testing.T, g *Game, state GameState) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for g.state != state && time.Now().Before(deadline) {
+		update(t, g, 1)
+		time.Sleep(time.Millisecond) // lets the scan goroutine run
+	}
+	if g.state != state {
+		t.Fatalf("stuck in %v, want %v", g.state, state)
+	}
+}
+
+func TestResumeRoundTrip(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	g.savePath = filepath.Join(t.TempDir(), "save.json")
+	g.seed = 1234
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+	update(t, g, 5*60)
+	g.score = 700
+	elapsed := g.runElapsed()
+	g.shutdown() // closing the window mid-run
+
+	saved, err := loadSave(g.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RunSnapshot{Mode: "DESTRUCTION", Target: root, Elapsed: elapsed, Score: 700, Seed: 1234}
+	if saved.Resume == nil || *saved.Resume != want {
+		t.Fatalf("saved snapshot %+v, want %+v", saved.Resume, want)
+	}
+
+	// Next launch
+	next := newGame(newFakeInput(), saved)
+	next.screenWidth, next.screenHeight = 1920, 1080
+	next.resumeRun()
+	defer next.cancelScan()
+	playUntil(t, next, StatePlaying)
+	if next.currentMode != ModeDestruction || next.seed != 1234 || next.finalFilesystemPath != root {
+		t.Errorf("resumed %v seed %d on %q", modeNames[next.currentMode], next.seed, next.finalFilesystemPath)
+	}
+	if next.score != 700 {
+		t.Errorf("resumed score %d, want 700", next.score)
+	}
+	if got := next.runElapsed(); got < elapsed || got > elapsed+time.Second {
+		t.Errorf("resumed clock at %v, want it carrying on from %v", got, elapsed)
+	}
+
+	next.finishRun(false)
+	if next.save.Resume != nil {
+		t.Error("the snapshot outlived the run")
+	}
+}
+
+func TestResumeGoneTarget(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.Resume = &RunSnapshot{Mode: "SAFE", Target: filepath.Join(t.TempDir(), "gone")}
+	g.resumeRun()
+	if g.save.Resume != nil || g.state != StateMenu {
+		t.Errorf("bad snapshot left resume %+v in %v", g.save.Resume, g.state)
+	}
+}
+
+func TestScanErrorBackToModeSelect(t *testing.T) {
+	g, in := testGame(t)
+	tap(t, g, in, ebiten.KeyRight) // DESTRUCTION
+	g.engage(filepath.Join(t.TempDir(), "gone"))
+	playUntil(t, g, StateFSError)
+
+	tap(t, g, in, ebiten.KeyEnter)
+	if g.state != StateMenu || g.inputActive || g.confirmActive {
+		t.Fatalf("enter on the scan error left state %v prompt %v confirm %v, want mode selection", g.state, g.inputActive, g.confirmActive)
+	}
+	if g.terminalColor != g.theme().Foreground {
+		t.Errorf("terminal color %v still the mode accent", g.terminalColor)
+	}
+	if g.inputBuffer != "" {
+		t.Errorf("input %q left over", g.inputBuffer)
+	}
+}
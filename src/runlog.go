@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Only this many run logs are kept, the oldest go first
+const runLogsKept = 50
+
+// runReport is what goes into a run's log
+type runReport struct {
+	Finished time.Time
+	Mode     Mode
+	Target   string
+	Won      bool
+	Duration time.Duration
+	Scanned  int // nodes found by the scan
+	Secured  int
+	Removed  int // deleted through the console
+	Score    int
+	Seed     int64
+}
+
+// formatRunLog renders a report as the plain text that ends up on disk
+func formatRunLog(r runReport) string {
+	outcome := "LOST"
+	if r.Won {
+		outcome = "WON"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "TERMI-WAR RUN LOG\n")
+	fmt.Fprintf(&b, "finished: %s\n", r.Finished.Format(time.RFC3339))
+	fmt.Fprintf(&b, "mode:     %s\n", modeNames[r.Mode])
+	fmt.Fprintf(&b, "target:   %s\n", r.Target)
+	fmt.Fprintf(&b, "outcome:  %s\n", outcome)
+	fmt.Fprintf(&b, "duration: %s\n", r.Duration.Round(10*time.Millisecond))
+	fmt.Fprintf(&b, "scanned:  %d\n", r.Scanned)
+	fmt.Fprintf(&b, "secured:  %d\n", r.Secured)
+	fmt.Fprintf(&b, "removed:  %d\n", r.Removed)
+	if r.Mode == ModeDestruction {
+		fmt.Fprintf(&b, "score:    %d\n", r.Score)
+	}
+	fmt.Fprintf(&b, "seed:     %d\n", r.Seed)
+	return b.String()
+}
+
+// writeRunLog saves r into dir as <timestamp>.log and then trims dir down to
+// the newest keep logs. The timestamps sort by name, so no stat calls needed.
+func writeRunLog(dir string, r runReport, keep int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	name := r.Finished.Format("20060102-150405.000") + ".log"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(formatRunLog(r)), 0o644); err != nil {
+		return err
+	}
+
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return err
+	}
+	slices.Sort(logs)
+	for _, old := range logs[:max(0, len(logs)-keep)] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logRun writes the log for the run that just ended. A failure only gets
+// logged to stderr, it never stops the game.
+func (g *Game) logRun(won bool) {
+	dir, err := configFile("runs")
+	if err != nil {
+		return
+	}
+
+	g.fsMutex.RLock()
+	r := runReport{
+		Finished: time.Now(),
+		Mode:     g.currentMode,
+		Target:   g.finalFilesystemPath,
+		Won:      won,
+		Duration: g.runDuration,
+		Scanned:  g.fsNodeCount,
+		Removed:  g.fsNodeCount - len(g.fsNodes),
+		Score:    g.score,
+		Seed:     g.seed,
+	}
+	for _, node := range g.fsNodes {
+		if node.Secured {
+			r.Secured++
+		}
+	}
+	g.fsMutex.RUnlock()
+
+	if err := writeRunLog(dir, r, runLogsKept); err != nil {
+		log.Println("failed to write run log:", err)
+	}
+}
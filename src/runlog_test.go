@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRunLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs")
+	finished := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	r := runReport{
+		Finished: finished,
+		Mode:     ModeDestruction,
+		Target:   "/home/op",
+		Won:      true,
+		Duration: 83*time.Second + 456*time.Millisecond,
+		Scanned:  120,
+		Secured:  3,
+		Removed:  9,
+		Score:    4200,
+		Seed:     42,
+	}
+	if err := writeRunLog(dir, r, runLogsKept); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "20260304-050607.000.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `TERMI-WAR RUN LOG
+finished: 2026-03-04T05:06:07Z
+mode:     DESTRUCTION
+target:   /home/op
+outcome:  WON
+duration: 1m23.46s
+scanned:  120
+secured:  3
+removed:  9
+score:    4200
+seed:     42
+`
+	if string(data) != want {
+		t.Errorf("log reads\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestRunLogsRotate(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		r := runReport{Finished: start.Add(time.Duration(i) * time.Minute), Mode: ModeSafe}
+		if err := writeRunLog(dir, r, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("%d logs kept, want 3", len(logs))
+	}
+	if first := filepath.Base(logs[0]); first != "20260101-000200.000.log" {
+		t.Errorf("oldest kept log is %s, want the third run", first)
+	}
+}
+
+func TestFinishedRunWritesLog(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	update(t, g, 60)
+	g.finishRun(false)
+
+	dir, err := configFile("runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	if len(logs) != 1 {
+		t.Fatalf("%d run logs after one run, want 1", len(logs))
+	}
+	data, err := os.ReadFile(logs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"mode:     SAFE", "target:   " + attractRoot, "outcome:  LOST", "duration: 1s"} {
+		if !strings.Contains(string(data), want+"\n") {
+			t.Errorf("log missing %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// SaveData is everything persisted between runs
+type SaveData struct {
+	Modes      map[string]*ModeRecord `json:"modes"` // keyed by mode name
+	TotalNodes int                    `json:"total_nodes"`
+	Settings   Settings               `json:"settings"`
+	LastMode   string                 `json:"last_mode,omitempty"` // mode name of the most recent run
+	Unlocks    []string               `json:"unlocks,omitempty"`   // ids from unlockRules that have been earned
+
+	TutorialSeen bool `json:"tutorial_seen"` // cleared again from the settings to bring it back
+
+	Resume *RunSnapshot `json:"resume,omitempty"` // the run the game was closed during
+}
+
+// ModeRecord tracks completed runs for a single mode
+type ModeRecord struct {
+	Completions int           `json:"completions"`
+	BestTime    time.Duration `json:"best_time"`            // zero until the mode has been won
+	BestScore   int           `json:"best_score,omitempty"` // DESTRUCTION only
+}
+
+// newSaveData is a fresh save, also the defaults for anything an older save
+// file doesn't have
+func newSaveData() *SaveData {
+	return &SaveData{Modes: map[string]*ModeRecord{}, Settings: Settings{Volume: defaultVolume}}
+}
+
+// saveFilePath is where the save lives, under the user's config directory
+func saveFilePath() (string, error) {
+	return configFile("save.json")
+}
+
+// loadSave reads the save file at path. A missing file isn't an error, it
+// just means this is the first run.
+func loadSave(path string) (*SaveData, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newSaveData(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	save := newSaveData()
+	save.TutorialSeen = true // a save from before the tutorial means they've played already
+	if err := json.Unmarshal(data, save); err != nil {
+		return nil, err
+	}
+	if save.Modes == nil {
+		save.Modes = map[string]*ModeRecord{}
+	}
+	save.refreshUnlocks() // saves from before unlocks existed may already qualify
+	return save, nil
+}
+
+// writeSave writes the save to path atomically: it goes to a temp file in the
+// same directory first and is renamed over the old one, so a crash mid-write
+// can't leave a half written save behind
+func writeSave(path string, save *SaveData) error {
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "save-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once the rename has happened
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lastMode is the mode of the most recent run, if there has been one
+func (s *SaveData) lastMode() (Mode, bool) {
+	i := slices.Index(modeNames, s.LastMode)
+	return Mode(i), i >= 0
+}
+
+// recordRun folds a finished run into the save
+func (s *SaveData) recordRun(mode Mode, won bool, elapsed time.Duration, nodes, score int) {
+	s.TotalNodes += nodes
+	s.LastMode = modeNames[mode]
+	if !won {
+		return
+	}
+
+	record := s.Modes[modeNames[mode]]
+	if record == nil {
+		record = &ModeRecord{}
+		s.Modes[modeNames[mode]] = record
+	}
+	record.Completions++
+	if record.BestTime == 0 || elapsed < record.BestTime {
+		record.BestTime = elapsed
+	}
+	record.BestScore = max(record.BestScore, score)
+	s.refreshUnlocks()
+}
+
+const unlockAmber = "amber_theme"
+
+// unlockRules say what earns each unlock, checked against the save after
+// every win
+var unlockRules = map[string]func(s *SaveData) bool{
+	unlockAmber: func(s *SaveData) bool {
+		record := s.Modes[modeNames[ModeDanger]]
+		return record != nil && record.Completions > 0
+	},
+}
+
+// refreshUnlocks records any unlocks the save has earned. Once earned they
+// stay, even if the records behind them are reset.
+func (s *SaveData) refreshUnlocks() {
+	for id, earned := range unlockRules {
+		if !s.unlocked(id) && earned(s) {
+			s.Unlocks = append(s.Unlocks, id)
+		}
+	}
+	slices.Sort(s.Unlocks) // map order is random, keep the file stable
+}
+
+func (s *SaveData) unlocked(id string) bool {
+	return slices.Contains(s.Unlocks, id)
+}
+
+// bestTime returns the fastest win for mode, if there is one
+func (s *SaveData) bestTime(mode Mode) (time.Duration, bool) {
+	record := s.Modes[modeNames[mode]]
+	if record == nil || record.BestTime == 0 {
+		return 0, false
+	}
+	return record.BestTime, true
+}
+
+// shutdown flushes everything that needs to survive the game closing,
+// including a run that's still going so it can be resumed. It's
+// called from both the quit key and the window close button and only does
+// the work once, so calling it again is harmless.
+func (g *Game) shutdown() {
+	g.shutdownOnce.Do(func() {
+		g.snapshotRun()
+		g.writeSaveFile()
+	})
+}
+
+// bestScore returns the highest winning score for mode, if there is one
+func (s *SaveData) bestScore(mode Mode) (int, bool) {
+	record := s.Modes[modeNames[mode]]
+	if record == nil || record.BestScore == 0 {
+		return 0, false
+	}
+	return record.BestScore, true
+}
+
+// finishRun records the run that just ended, writes the save to disk and
+// leaves a log of the run next to it
+func (g *Game) finishRun(won bool) {
+	g.fsMutex.RLock()
+	nodes := g.fsNodeCount
+	g.fsMutex.RUnlock()
+
+	g.runDuration = g.runElapsed()
+	best, _ := g.save.bestScore(g.currentMode)
+	g.newBestScore = won && g.score > best
+	g.save.recordRun(g.currentMode, won, g.runDuration, nodes, g.score)
+	g.save.Resume = nil
+	g.writeSaveFile()
+	g.logRun(won)
+}
+
+// writeSaveFile writes the save to disk, logging rather than failing since
+// there's nothing the player can do about it mid-game
+func (g *Game) writeSaveFile() {
+	if g.savePath == "" {
+		return
+	}
+	if err := writeSave(g.savePath, g.save); err != nil {
+		log.Println("failed to write save:", err)
+	}
+}
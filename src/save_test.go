@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "termi-war", "save.json")
+	save := newSaveData()
+	save.recordRun(ModeSafe, true, 42*time.Second, 120, 0)
+	save.recordRun(ModeSafe, true, 30*time.Second, 80, 0)
+	save.recordRun(ModeDanger, false, time.Minute, 50, 0)
+	save.Settings.Theme = "IBM BLUE"
+
+	if err := writeSave(path, save); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, save) {
+		t.Fatalf("loaded %+v, want %+v", loaded, save)
+	}
+	if r := loaded.Modes["SAFE"]; r.Completions != 2 || r.BestTime != 30*time.Second {
+		t.Errorf("SAFE record %+v, want 2 completions and a best of 30s", r)
+	}
+	if loaded.TotalNodes != 250 {
+		t.Errorf("total nodes %d, want 250", loaded.TotalNodes)
+	}
+
+	// The write goes through a temp file, nothing should be left next to it
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("save directory has %d entries, want just save.json", len(entries))
+	}
+}
+
+func TestLoadMissingSave(t *testing.T) {
+	save, err := loadSave(filepath.Join(t.TempDir(), "save.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(save, newSaveData()) {
+		t.Fatalf("missing save loaded as %+v, want a fresh one", save)
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	g, _ := testGame(t)
+	g.savePath = filepath.Join(t.TempDir(), "save.json")
+	g.save.TotalNodes = 7
+	g.shutdown()
+	g.save.TotalNodes = 8 // the second flush is a no-op, so this never lands
+	g.shutdown()
+
+	saved, err := loadSave(g.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.TotalNodes != 7 {
+		t.Errorf("saved %d total nodes, want the 7 from the first flush", saved.TotalNodes)
+	}
+}
+
+func TestQuitKeyFlushes(t *testing.T) {
+	g, in := testGame(t)
+	g.savePath = filepath.Join(t.TempDir(), "save.json")
+	in.press(ebiten.KeyQ)
+	if err := g.Update(); err != ebiten.Termination {
+		t.Fatalf("Q on the menu returned %v, want termination", err)
+	}
+	if _, err := os.Stat(g.savePath); err != nil {
+		t.Errorf("quitting didn't write the save: %v", err)
+	}
+	g.shutdown() // the close button can still fire afterwards
+}
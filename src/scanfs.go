@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scanFS is what initalizeFilesystem walks. diskFS is the real thing; memFS
+// is a made up tree for attract mode and anything else that shouldn't touch
+// the disk.
+type scanFS interface {
+	// WalkDir behaves like filepath.WalkDir
+	WalkDir(root string, fn fs.WalkDirFunc) error
+	// EvalSymlinks behaves like filepath.EvalSymlinks
+	EvalSymlinks(path string) (string, error)
+}
+
+// diskFS walks the real filesystem, leaving out anything rules block
+type diskFS struct {
+	rules targetRules
+}
+
+func (d diskFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() && path != root && d.rules.blocked(path) {
+			return fs.SkipDir
+		}
+		return fn(path, entry, err)
+	})
+}
+
+func (diskFS) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+// memFS is an in-memory tree of absolute slash separated paths
+type memFS struct {
+	entries  map[string]memEntry
+	children map[string][]string // directory path to sorted child names
+}
+
+type memEntry struct {
+	mode   fs.FileMode
+	size   int64
+	target string // symlinks only, absolute
+}
+
+// newMemFS builds a tree from spec, one absolute path per line. A trailing /
+// makes a directory, a number after the path is a file's size and "-> path"
+// makes a symlink. Missing parent directories are filled in and blank lines
+// are ignored:
+//
+//	/home/op/
+//	/home/op/notes.txt 1204
+//	/home/op/loop -> /home/op
+func newMemFS(spec string) (*memFS, error) {
+	m := &memFS{entries: map[string]memEntry{}, children: map[string][]string{}}
+	for n, line := range strings.Split(spec, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		p := fields[0]
+		if !path.IsAbs(p) {
+			return nil, fmt.Errorf("line %d: %q isn't absolute", n+1, p)
+		}
+
+		var e memEntry
+		switch {
+		case strings.HasSuffix(p, "/") && len(fields) == 1:
+			e.mode = fs.ModeDir | 0o755
+		case len(fields) == 1:
+			e.mode = 0o644
+		case len(fields) == 2:
+			size, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad size %q", n+1, fields[1])
+			}
+			e.mode, e.size = 0o644, size
+		case len(fields) == 3 && fields[1] == "->":
+			e.mode, e.target = fs.ModeSymlink|0o777, path.Clean(fields[2])
+		default:
+			return nil, fmt.Errorf("line %d: can't make sense of %q", n+1, line)
+		}
+		m.add(path.Clean(p), e)
+	}
+	return m, nil
+}
+
+// mustMemFS is newMemFS for specs built into the game
+func mustMemFS(spec string) *memFS {
+	m, err := newMemFS(spec)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// add puts e at p, creating its parents as directories where needed
+func (m *memFS) add(p string, e memEntry) {
+	if _, ok := m.entries[p]; !ok && p != "/" {
+		dir := path.Dir(p)
+		if _, ok := m.entries[dir]; !ok {
+			m.add(dir, memEntry{mode: fs.ModeDir | 0o755})
+		}
+		names := m.children[dir]
+		i, _ := slices.BinarySearch(names, path.Base(p))
+		m.children[dir] = slices.Insert(names, i, path.Base(p))
+	}
+	m.entries[p] = e
+}
+
+func (m *memFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	root = path.Clean(root)
+	e, ok := m.entries[root]
+	if !ok {
+		err := fn(root, nil, &fs.PathError{Op: "lstat", Path: root, Err: fs.ErrNotExist})
+		if err == fs.SkipDir || err == fs.SkipAll {
+			return nil
+		}
+		return err
+	}
+	err := m.walk(root, memDirEntry{path.Base(root), e}, fn)
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walk visits p and, for a directory, everything under it in lexical order,
+// the same way filepath.WalkDir does
+func (m *memFS) walk(p string, d memDirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(p, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir && d.IsDir() {
+			return nil
+		}
+		return err
+	}
+	for _, name := range m.children[p] {
+		child := path.Join(p, name)
+		if err := m.walk(child, memDirEntry{name, m.entries[child]}, fn); err != nil {
+			if err == fs.SkipDir {
+				break // skipping a file skips the rest of its directory
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// EvalSymlinks follows p if it's a link, only the last element is resolved
+func (m *memFS) EvalSymlinks(p string) (string, error) {
+	p = path.Clean(p)
+	for range 255 {
+		e, ok := m.entries[p]
+		if !ok {
+			return "", &fs.PathError{Op: "lstat", Path: p, Err: fs.ErrNotExist}
+		}
+		if e.mode&fs.ModeSymlink == 0 {
+			return p, nil
+		}
+		p = e.target
+	}
+	return "", &fs.PathError{Op: "readlink", Path: p, Err: fmt.Errorf("too many links")}
+}
+
+// memDirEntry is both the fs.DirEntry and the fs.FileInfo for a memFS node
+type memDirEntry struct {
+	name string
+	memEntry
+}
+
+func (d memDirEntry) Name() string               { return d.name }
+func (d memDirEntry) IsDir() bool                { return d.mode.IsDir() }
+func (d memDirEntry) Type() fs.FileMode          { return d.mode.Type() }
+func (d memDirEntry) Info() (fs.FileInfo, error) { return d, nil }
+func (d memDirEntry) Size() int64                { return d.size }
+func (d memDirEntry) Mode() fs.FileMode          { return d.mode }
+func (d memDirEntry) ModTime() time.Time         { return time.Time{} }
+func (d memDirEntry) Sys() any                   { return nil }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+const memSpec = `
+/home/op/
+/home/op/notes.txt 1204
+/home/op/src/main.go 80
+/home/op/loop -> /home/op
+
+/home/op/empty/
+`
+
+// walked lists what WalkDir visits as "path mode size"
+func walked(t *testing.T, fsys scanFS, root string) []string {
+	t.Helper()
+	var got []string
+	err := fsys.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		got = append(got, fmt.Sprintf("%s %v %d", p, d.Type(), info.Size()))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestMemFSTree(t *testing.T) {
+	fsys, err := newMemFS(memSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/home/op d--------- 0",
+		"/home/op/empty d--------- 0",
+		"/home/op/loop L--------- 0",
+		"/home/op/notes.txt ---------- 1204",
+		"/home/op/src d--------- 0",
+		"/home/op/src/main.go ---------- 80",
+	}
+	if got := walked(t, fsys, "/home/op"); !slices.Equal(got, want) {
+		t.Errorf("walk gave\n%q\nwant\n%q", got, want)
+	}
+
+	if real, err := fsys.EvalSymlinks("/home/op/loop"); err != nil || real != "/home/op" {
+		t.Errorf("EvalSymlinks(loop) = %q, %v", real, err)
+	}
+	if _, err := fsys.EvalSymlinks("/home/op/nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("EvalSymlinks of a missing path: %v", err)
+	}
+	err = fsys.WalkDir("/nope", func(p string, d fs.DirEntry, err error) error { return err })
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("walking a missing root: %v", err)
+	}
+}
+
+func TestMemFSSkipDir(t *testing.T) {
+	fsys := mustMemFS(memSpec)
+	var got []string
+	fsys.WalkDir("/home/op", func(p string, d fs.DirEntry, err error) error {
+		got = append(got, p)
+		if p == "/home/op/src" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if slices.Contains(got, "/home/op/src/main.go") {
+		t.Errorf("SkipDir on src still walked into it: %q", got)
+	}
+}
+
+func TestMemFSBadSpec(t *testing.T) {
+	for _, spec := range []string{"relative/path", "/a big", "/a -> ", "/a 1 2 3"} {
+		if _, err := newMemFS(spec); err == nil {
+			t.Errorf("newMemFS(%q) accepted it", spec)
+		}
+	}
+}
+
+func TestScanMemFS(t *testing.T) {
+	g, _ := testGame(t)
+	scan(t, g, mustMemFS(memSpec), "/home/op")
+	if !g.fsReady {
+		t.Fatal(g.fsErr)
+	}
+	if g.fsNodeCount != 6 || g.fsStats.Files != 3 || g.fsStats.Dirs != 3 || g.fsStats.Bytes != 1284 {
+		t.Errorf("scanned %d nodes, stats %+v", g.fsNodeCount, g.fsStats)
+	}
+	if i := g.findNode("/home/op/loop"); i < 0 || !g.fsNodes[i].Loop {
+		t.Error("the link back to the target isn't marked as a loop")
+	}
+	if i := g.findNode("/home/op/src/main.go"); i < 0 || g.fsNodes[i].Parent != "/home/op/src" {
+		t.Error("main.go isn't recorded under src")
+	}
+}
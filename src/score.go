@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+// DESTRUCTION scoring: every node processed is worth pointsPerNode times the
+// combo, and the combo climbs while actions keep coming within comboWindow
+const (
+	pointsPerNode = 100
+	comboWindow   = 2 * time.Second
+	maxCombo      = 8
+)
+
+// nextCombo is the combo after an action that came since after the previous
+// one. Too slow and the chain starts over at 1.
+func nextCombo(combo int, since time.Duration) int {
+	if combo == 0 || since > comboWindow {
+		return 1
+	}
+	return min(combo+1, maxCombo)
+}
+
+// pointsFor is what one action scores at the given combo
+func pointsFor(combo int) int {
+	return pointsPerNode * combo
+}
+
+// scoreAction credits one processed node, only DESTRUCTION keeps score
+func (g *Game) scoreAction() {
+	if g.currentMode != ModeDestruction {
+		return
+	}
+	now := g.now()
+	g.combo = nextCombo(g.combo, now.Sub(g.lastActionTime))
+	g.score += pointsFor(g.combo)
+	g.lastActionTime = now
+}
+
+// comboActive reports whether the chain is still alive
+func (g *Game) comboActive() bool {
+	return g.combo > 1 && g.since(g.lastActionTime) <= comboWindow
+}
+
+// drawScore puts the score big and centered at the top, where DANGER has its
+// countdown, with the combo under it while it lasts
+func (g *Game) drawScore(screen *ebiten.Image) {
+	face := getFace(titleFontSize)
+	str := fmt.Sprintf(tr("playing.score"), g.score)
+	x := (g.screenWidth - font.MeasureString(face, str).Ceil()) / 2
+	text.Draw(screen, str, face, x, g.marginY()+face.Metrics().Ascent.Ceil(), g.theme().Foreground)
+	if g.comboActive() {
+		combo := fmt.Sprintf(tr("playing.combo"), g.combo)
+		x := (g.screenWidth - font.MeasureString(mplusNormalFont, combo).Ceil()) / 2
+		text.Draw(screen, combo, mplusNormalFont, x, g.lineY(2), g.theme().Warning)
+	}
+}
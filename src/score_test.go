@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextCombo(t *testing.T) {
+	tests := []struct {
+		combo int
+		since time.Duration
+		want  int
+	}{
+		{0, 0, 1},
+		{1, time.Second, 2},
+		{3, comboWindow, 4},
+		{3, comboWindow + time.Millisecond, 1},
+		{maxCombo, 0, maxCombo},
+		{maxCombo - 1, 0, maxCombo},
+	}
+	for _, tt := range tests {
+		if got := nextCombo(tt.combo, tt.since); got != tt.want {
+			t.Errorf("nextCombo(%d, %v) = %d, want %d", tt.combo, tt.since, got, tt.want)
+		}
+	}
+	if got := pointsFor(3); got != 3*pointsPerNode {
+		t.Errorf("pointsFor(3) = %d, want %d", got, 3*pointsPerNode)
+	}
+}
+
+func TestScoreActionCombos(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeDestruction, attractFS, attractRoot)
+
+	g.scoreAction()
+	update(t, g, 30) // half a second, well inside the window
+	g.scoreAction()
+	if g.combo != 2 || g.score != pointsFor(1)+pointsFor(2) {
+		t.Fatalf("quick pair: combo %d score %d, want 2 and %d", g.combo, g.score, pointsFor(1)+pointsFor(2))
+	}
+
+	update(t, g, int(comboWindow/tickLength())+5)
+	if g.comboActive() {
+		t.Error("combo still active after the window closed")
+	}
+	before := g.score
+	g.scoreAction()
+	if g.combo != 1 || g.score != before+pointsFor(1) {
+		t.Errorf("after a pause: combo %d, +%d points, want 1 and %d", g.combo, g.score-before, pointsFor(1))
+	}
+
+	g.save.recordRun(ModeDestruction, true, time.Minute, 10, g.score)
+	if best := g.save.Modes[modeNames[ModeDestruction]].BestScore; best != g.score {
+		t.Errorf("best score %d, want %d", best, g.score)
+	}
+}
+
+func TestOnlyDestructionScores(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.scoreAction()
+	if g.score != 0 || g.combo != 0 {
+		t.Errorf("SAFE scored %d at combo %d", g.score, g.combo)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import "strings"
+
+// matchesFilter reports whether path contains query, ignoring case. An empty
+// query matches everything.
+func matchesFilter(path, query string) bool {
+	return strings.Contains(strings.ToLower(path), strings.ToLower(query))
+}
+
+// viewKey is everything the listing depends on. While it stays the same the
+// listing does too.
+type viewKey struct {
+	dir       string
+	filter    string
+	sort      listSort
+	dirsFirst bool
+	version   int // fsVersion
+}
+
+// viewNodes is the listing as the player sees it, the indexes into fsNodes
+// inside the directory being browsed that pass the current filter, in the
+// current sort order. The full list is never touched, so clearing the filter
+// brings everything back. Callers hold fsMutex and mustn't change the slice.
+//
+// It's asked for several times a frame, so the listing is only rebuilt once
+// something it depends on changes. Only the game loop calls this, which is
+// what makes filling in the cache under a read lock safe.
+func (g *Game) viewNodes() []int {
+	key := viewKey{g.browseDir(), g.filter, g.listSort, g.save.Settings.DirsFirst, g.fsVersion}
+	if g.viewCache != nil && key == g.viewCacheKey {
+		return g.viewCache
+	}
+	view := make([]int, 0, len(g.fsNodes))
+	for i, node := range g.fsNodes {
+		if (key.dir == "" || node.Parent == key.dir) && matchesFilter(node.Path, key.filter) {
+			view = append(view, i)
+		}
+	}
+	g.sortView(view)
+	g.viewCache, g.viewCacheKey = view, key
+	return view
+}
+
+// updateSearch handles the / prompt, filtering live as the player types.
+// Confirm keeps the filter and goes back to the listing, Cancel drops it.
+func (g *Game) updateSearch() {
+	if g.justPressed(ActionCancel) {
+		g.searchActive = false
+		g.setInput("")
+		g.setFilter("")
+		return
+	}
+
+	g.updateTextInput()
+	g.setFilter(g.inputBuffer)
+
+	if g.justPressed(ActionConfirm) {
+		g.searchActive = false
+		g.setInput("")
+	}
+}
+
+// setFilter changes the filter and puts the cursor back at the top of the
+// new view
+func (g *Game) setFilter(query string) {
+	if query == g.filter {
+		return
+	}
+	g.filter = query
+	g.selectedNode = 0
+	g.listOffset = 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	tests := []struct {
+		path, query string
+		want        bool
+	}{
+		{"/home/user/Notes.txt", "notes", true},
+		{"/home/user/Notes.txt", "NOTES", true},
+		{"/home/user/Notes.txt", "es.T", true},
+		{"/home/user/Notes.txt", "user/no", true},
+		{"/home/user/Notes.txt", "", true},
+		{"/home/user/Notes.txt", "notes.md", false},
+		{"/home/user/Notes.txt", "  notes", false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilter(tt.path, tt.query); got != tt.want {
+			t.Errorf("matchesFilter(%q, %q) = %v, want %v", tt.path, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFiltersAndClears(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, diskFS{}, writeTree(t, map[string]string{
+		"README.md": "", "readme.txt": "", "main.go": "",
+	}))
+	total, nodes := len(g.viewNodes()), len(g.fsNodes)
+
+	tap(t, g, in, ebiten.KeySlash)
+	typeText(t, g, in, "ReAdMe")
+	if got := len(g.viewNodes()); got != 2 {
+		t.Errorf("filter %q shows %d nodes, want 2", g.filter, got)
+	}
+	tap(t, g, in, ebiten.KeyEscape)
+	if g.filter != "" || len(g.viewNodes()) != total {
+		t.Errorf("escape left filter %q showing %d of %d nodes", g.filter, len(g.viewNodes()), total)
+	}
+	if len(g.fsNodes) != nodes {
+		t.Errorf("filtering changed the model from %d to %d nodes", nodes, len(g.fsNodes))
+	}
+}
+
+// sameSlice reports whether a and b are the very same listing, not just equal
+func sameSlice(a, b []int) bool {
+	return len(a) > 0 && len(a) == len(b) && &a[0] == &b[0]
+}
+
+func TestViewNodesCached(t *testing.T) {
+	g, _ := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	view := g.viewNodes()
+	if !sameSlice(g.viewNodes(), view) {
+		t.Fatal("asking twice built the listing twice")
+	}
+
+	for _, c := range []struct {
+		name   string
+		change func()
+	}{
+		{"the filter", func() { g.filter = "a" }},
+		{"the sort", func() { g.listSort = sortSize }},
+		{"directories first", func() { g.save.Settings.DirsFirst = !g.save.Settings.DirsFirst }},
+		{"removing a node", func() { g.removeNode(len(g.fsNodes) - 1) }},
+	} {
+		c.change()
+		next := g.viewNodes()
+		if sameSlice(next, view) {
+			t.Errorf("changing %s kept the old listing", c.name)
+		}
+		view = next
+	}
+
+	// Whatever was cached, the listing is what a fresh build would give
+	g.viewCache = nil
+	if fresh := g.viewNodes(); !slices.Equal(fresh, view) {
+		t.Errorf("the cached listing %v, built fresh %v", view, fresh)
+	}
+}
+
+func TestViewNodesAfterUndo(t *testing.T) {
+	root := realTree(t, victimFiles)
+	g, _ := testGame(t)
+	startRun(t, g, ModeDestruction, diskFS{}, root)
+	before := slices.Clone(g.viewNodes())
+	g.cmdRm([]string{filepath.Base(victim(t, g))})
+	if len(g.viewNodes()) != len(before)-1 {
+		t.Fatalf("the listing has %d nodes after rm, had %d", len(g.viewNodes()), len(before))
+	}
+	g.undoDelete()
+	if !slices.Equal(g.viewNodes(), before) {
+		t.Errorf("after undo the listing is %v, was %v", g.viewNodes(), before)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+// Settings are the player's preferences, stored in the save file
+type Settings struct {
+	Theme        string `json:"theme"`
+	DisableShake bool   `json:"disable_shake"`
+	Muted        bool   `json:"muted"` // silences everything without losing Volume
+
+	Volume int `json:"volume"` // 0 to 100
+
+	DisableTicks bool `json:"disable_ticks"` // no teletype tick while text is typed out
+
+	DisableScanlines bool `json:"disable_scanlines"` // skips the CRT shader entirely
+
+	// No blinking, flashing or shaking, for players sensitive to motion or flicker
+	ReducedMotion bool `json:"reduced_motion"`
+
+	Hum string `json:"hum"` // one of humLevels, empty means LOW
+
+	FastBoot bool `json:"fast_boot"` // typed sequences run at fastBootScale
+
+	BossScreen string `json:"boss_screen"` // one of bossScreens, empty means DARK
+
+	DirsFirst bool `json:"dirs_first"` // the listing puts directories ahead of files whatever the sort
+
+	FileTargets bool `json:"file_targets"` // a regular file can be the target, instead of only directories
+
+	FrameRate string `json:"frame_rate"` // one of frameRates, empty means 60
+
+	Fullscreen bool `json:"fullscreen"` // restored on the next launch
+}
+
+// settingOption is one row of the settings screen
+type settingOption struct {
+	name   string // message id of the label
+	value  func(g *Game) string
+	change func(g *Game, step int) // step is -1 or 1
+	locked func(g *Game) bool      // greys the row out, nil if it never is
+}
+
+var settingOptions = []settingOption{
+	{"settings.theme", func(g *Game) string {
+		name := themes[g.themeCursor].Name
+		if hint, locked := themeLocked(g.save, g.themeCursor); locked {
+			return name + " (" + tr(hint) + ")"
+		}
+		return name
+	}, func(g *Game, step int) { g.cycleTheme(step) }, func(g *Game) bool {
+		_, locked := themeLocked(g.save, g.themeCursor)
+		return locked
+	}},
+	{"settings.shake", func(g *Game) string { return onOff(!g.save.Settings.DisableShake) }, func(g *Game, step int) {
+		g.save.Settings.DisableShake = !g.save.Settings.DisableShake
+	}, nil},
+	{"settings.volume", func(g *Game) string {
+		if g.save.Settings.Muted {
+			return "MUTED"
+		}
+		return fmt.Sprintf("%d%%", g.save.Settings.Volume)
+	}, func(g *Game, step int) { g.setVolume(g.save.Settings.Volume + step*volumeStep) }, nil},
+	{"settings.ticks", func(g *Game) string { return onOff(!g.save.Settings.DisableTicks) }, func(g *Game, step int) {
+		g.save.Settings.DisableTicks = !g.save.Settings.DisableTicks
+	}, nil},
+	{"settings.scanlines", func(g *Game) string {
+		if !shadersAvailable {
+			return "UNAVAILABLE"
+		}
+		return onOff(!g.save.Settings.DisableScanlines)
+	}, func(g *Game, step int) {
+		g.save.Settings.DisableScanlines = !g.save.Settings.DisableScanlines
+	}, nil},
+	{"settings.hum", func(g *Game) string {
+		if g.save.Settings.Hum == "" {
+			return "LOW"
+		}
+		return g.save.Settings.Hum
+	}, func(g *Game, step int) { g.cycleHum(step) }, nil},
+	{"settings.frame_rate", func(g *Game) string {
+		if g.save.Settings.FrameRate == "" {
+			return frameRates[0]
+		}
+		return g.save.Settings.FrameRate
+	}, func(g *Game, step int) { g.cycleFrameRate(step) }, nil},
+	{"settings.boss_screen", func(g *Game) string {
+		return bossScreens[bossScreenIndex(g.save.Settings.BossScreen)].Name
+	}, func(g *Game, step int) { g.cycleBossScreen(step) }, nil},
+	{"settings.fast_boot", func(g *Game) string { return onOff(g.save.Settings.FastBoot) }, func(g *Game, step int) {
+		g.save.Settings.FastBoot = !g.save.Settings.FastBoot
+	}, nil},
+	{"settings.dirs_first", func(g *Game) string { return onOff(g.save.Settings.DirsFirst) }, func(g *Game, step int) {
+		g.save.Settings.DirsFirst = !g.save.Settings.DirsFirst
+	}, nil},
+	{"settings.file_targets", func(g *Game) string {
+		if g.save.Settings.FileTargets {
+			return "SCAN"
+		}
+		return "REJECT"
+	}, func(g *Game, step int) {
+		g.save.Settings.FileTargets = !g.save.Settings.FileTargets
+	}, nil},
+	{"settings.tutorial", func(g *Game) string { return onOff(!g.save.TutorialSeen) }, func(g *Game, step int) {
+		g.save.TutorialSeen = !g.save.TutorialSeen
+	}, nil},
+	{"settings.reduced_motion", func(g *Game) string { return onOff(g.save.Settings.ReducedMotion) }, func(g *Game, step int) {
+		g.save.Settings.ReducedMotion = !g.save.Settings.ReducedMotion
+	}, nil},
+}
+
+func onOff(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
+
+func (g *Game) updateSettings() {
+	if g.justPressed(ActionCancel) {
+		g.writeSaveFile()
+		g.pushToast(tr("toast.saved"), toastTime)
+		g.returnToMenu()
+		return
+	}
+
+	if g.justPressed(ActionMoveDown) {
+		g.selectedSetting = (g.selectedSetting + 1) % len(settingOptions)
+	}
+	if g.justPressed(ActionMoveUp) {
+		g.selectedSetting = (g.selectedSetting - 1 + len(settingOptions)) % len(settingOptions)
+	}
+
+	option := settingOptions[g.selectedSetting]
+	if g.justPressed(ActionMoveRight) || g.justPressed(ActionConfirm) {
+		option.change(g, 1)
+	}
+	if g.justPressed(ActionMoveLeft) {
+		option.change(g, -1)
+	}
+}
+
+func (g *Game) drawSettings(screen *ebiten.Image) {
+	theme := g.theme()
+	text.Draw(screen, tr("settings.title"), mplusNormalFont, g.marginX(), g.lineY(0), theme.Foreground)
+	for i, option := range settingOptions {
+		displayColor := theme.Dim
+		prefix := "  "
+		if i == g.selectedSetting {
+			displayColor = theme.Foreground
+			prefix = "> "
+		}
+		if option.locked != nil && option.locked(g) {
+			displayColor = theme.Dim
+		}
+		text.Draw(screen, prefix+tr(option.name)+": "+option.value(g), mplusNormalFont, g.marginX(), g.lineY(2+i), displayColor)
+	}
+	text.Draw(screen, tr("settings.return"), mplusNormalFont, g.marginX(), g.lineY(3+len(settingOptions)), theme.Dim)
+}
+
+// toggleFullscreen flips between fullscreen and a window, putting the window
+// back at the size it had before going fullscreen
+func (g *Game) toggleFullscreen() {
+	fullscreen := !ebiten.IsFullscreen()
+	if fullscreen {
+		g.windowedWidth, g.windowedHeight = ebiten.WindowSize()
+	}
+	ebiten.SetFullscreen(fullscreen)
+	if !fullscreen && g.windowedWidth > 0 && g.windowedHeight > 0 {
+		ebiten.SetWindowSize(g.windowedWidth, g.windowedHeight)
+	}
+
+	g.save.Settings.Fullscreen = fullscreen
+	g.writeSaveFile()
+}
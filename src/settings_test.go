@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestToggleEachSetting(t *testing.T) {
+	g, in := testGame(t)
+	g.savePath = filepath.Join(t.TempDir(), "save.json")
+	tap(t, g, in, ebiten.KeyS)
+	if g.state != StateSettings {
+		t.Fatalf("S went to %v, want settings", g.state)
+	}
+
+	for i, option := range settingOptions {
+		if g.selectedSetting != i {
+			t.Fatalf("cursor on row %d, want %d", g.selectedSetting, i)
+		}
+		before, settings, tutorial := option.value(g), g.save.Settings, g.save.TutorialSeen
+		tap(t, g, in, ebiten.KeyLeft) // left so volume has room to move from 100
+		changed := option.value(g) != before || g.save.Settings != settings || g.save.TutorialSeen != tutorial
+		if !changed {
+			t.Errorf("%s: left left it at %q", tr(option.name), before)
+		}
+		tap(t, g, in, ebiten.KeyDown)
+	}
+	if g.selectedSetting != 0 {
+		t.Errorf("down from the last row went to %d, want the first", g.selectedSetting)
+	}
+
+	want := g.save.Settings
+	tap(t, g, in, ebiten.KeyEscape)
+	if g.state != StateMenu {
+		t.Fatalf("escape went to %v, want the menu", g.state)
+	}
+	saved, err := loadSave(g.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Settings != want {
+		t.Errorf("saved settings %+v, want %+v", saved.Settings, want)
+	}
+}
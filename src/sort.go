@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// listSort is the order the listing is shown in. Sorting only ever reorders
+// the view, fsNodes stays in scan order.
+type listSort int
+
+const (
+	sortScan listSort = iota // the order the scan found things in
+	sortName
+	sortSize     // biggest first
+	sortModified // newest first
+)
+
+var listSortNames = []string{"SCAN", "NAME", "SIZE", "MODIFIED"}
+
+// Comparators for each order, ties compare equal so a stable sort leaves them
+// in scan order
+func compareName(a, b FSNode) int {
+	return strings.Compare(strings.ToLower(filepath.Base(a.Path)), strings.ToLower(filepath.Base(b.Path)))
+}
+
+func compareSize(a, b FSNode) int {
+	return cmp.Compare(b.Size, a.Size)
+}
+
+func compareModified(a, b FSNode) int {
+	return b.ModTime.Compare(a.ModTime)
+}
+
+// compareDirsFirst puts directories ahead of everything else
+func compareDirsFirst(a, b FSNode) int {
+	switch {
+	case a.IsDir && !b.IsDir:
+		return -1
+	case !a.IsDir && b.IsDir:
+		return 1
+	}
+	return 0
+}
+
+var listSortCompare = map[listSort]func(a, b FSNode) int{
+	sortName:     compareName,
+	sortSize:     compareSize,
+	sortModified: compareModified,
+}
+
+// sortView puts view, indexes into fsNodes, in the listing's current order.
+// Callers hold fsMutex.
+func (g *Game) sortView(view []int) {
+	compare := listSortCompare[g.listSort]
+	dirsFirst := g.save.Settings.DirsFirst
+	if compare == nil && !dirsFirst {
+		return
+	}
+	slices.SortStableFunc(view, func(i, j int) int {
+		a, b := g.fsNodes[i], g.fsNodes[j]
+		if dirsFirst {
+			if c := compareDirsFirst(a, b); c != 0 {
+				return c
+			}
+		}
+		if compare == nil {
+			return 0
+		}
+		return compare(a, b)
+	})
+}
+
+// cycleSort moves on to the next order, starting back at the top of the list
+func (g *Game) cycleSort() {
+	g.listSort = (g.listSort + 1) % listSort(len(listSortNames))
+	g.selectedNode = 0
+	g.listOffset = 0
+}
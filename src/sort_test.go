@@ -0,0 +1,98 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSortComparators(t *testing.T) {
+	at := func(s int) time.Time { return gameEpoch.Add(time.Duration(s) * time.Second) }
+	a := FSNode{Path: "/t/alpha", Size: 10, ModTime: at(1)}
+	b := FSNode{Path: "/t/Beta", Size: 30, ModTime: at(3)}
+	aUpper := FSNode{Path: "/u/ALPHA", Size: 10, ModTime: at(1)}
+	for _, c := range []struct {
+		name    string
+		compare func(a, b FSNode) int
+		x, y    FSNode
+		want    int
+	}{
+		{"name", compareName, a, b, -1},
+		{"name", compareName, b, a, 1},
+		{"name ignores case and dir", compareName, a, aUpper, 0},
+		{"size biggest first", compareSize, b, a, -1},
+		{"size", compareSize, a, b, 1},
+		{"size tie", compareSize, a, aUpper, 0},
+		{"modified newest first", compareModified, b, a, -1},
+		{"modified", compareModified, a, b, 1},
+		{"modified tie", compareModified, a, aUpper, 0},
+		{"dirs first", compareDirsFirst, FSNode{IsDir: true}, a, -1},
+		{"dirs first", compareDirsFirst, a, FSNode{IsDir: true}, 1},
+		{"dirs first tie", compareDirsFirst, a, b, 0},
+	} {
+		if got := c.compare(c.x, c.y); got != c.want {
+			t.Errorf("%s: compare(%s, %s) = %d, want %d", c.name, c.x.Path, c.y.Path, got, c.want)
+		}
+	}
+}
+
+// sortedPaths is the view of nodes in the given order
+func sortedPaths(g *Game, order listSort, dirsFirst bool) []string {
+	g.listSort = order
+	g.save.Settings.DirsFirst = dirsFirst
+	view := make([]int, len(g.fsNodes))
+	for i := range view {
+		view[i] = i
+	}
+	g.sortView(view)
+	var paths []string
+	for _, i := range view {
+		paths = append(paths, g.fsNodes[i].Path)
+	}
+	return paths
+}
+
+func TestSortView(t *testing.T) {
+	g, _ := testGame(t)
+	g.fsNodes = []FSNode{
+		{Path: "/t/c", Size: 5, ModTime: gameEpoch.Add(2 * time.Second)},
+		{Path: "/t/a", Size: 5, ModTime: gameEpoch.Add(time.Second)},
+		{Path: "/t/dir", IsDir: true},
+		{Path: "/t/b", Size: 9, ModTime: gameEpoch.Add(2 * time.Second)},
+	}
+	for _, c := range []struct {
+		order     listSort
+		dirsFirst bool
+		want      []string
+	}{
+		{sortScan, false, []string{"/t/c", "/t/a", "/t/dir", "/t/b"}},
+		{sortScan, true, []string{"/t/dir", "/t/c", "/t/a", "/t/b"}},
+		{sortName, false, []string{"/t/a", "/t/b", "/t/c", "/t/dir"}},
+		// c and a tie on size, so they stay in scan order
+		{sortSize, false, []string{"/t/b", "/t/c", "/t/a", "/t/dir"}},
+		{sortSize, true, []string{"/t/dir", "/t/b", "/t/c", "/t/a"}},
+		// c and b tie on time
+		{sortModified, false, []string{"/t/c", "/t/b", "/t/a", "/t/dir"}},
+	} {
+		if got := sortedPaths(g, c.order, c.dirsFirst); !slices.Equal(got, c.want) {
+			t.Errorf("%s (dirs first %v) gave %q, want %q", listSortNames[c.order], c.dirsFirst, got, c.want)
+		}
+	}
+}
+
+func TestSortKeyCycles(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeSafe, attractFS, attractRoot)
+	g.moveSelection(2)
+	for _, want := range []listSort{sortName, sortSize, sortModified, sortScan} {
+		tap(t, g, in, ebiten.KeyO)
+		if g.listSort != want {
+			t.Fatalf("sort key went to %s, want %s", listSortNames[g.listSort], listSortNames[want])
+		}
+		if g.selectedNode != 0 {
+			t.Errorf("changing the sort left the cursor on row %d", g.selectedNode)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// targetRules are directories the game must never scan, from targets.json in
+// the config directory, e.g.
+//
+//	{"blocked": ["/", "$HOME"], "allowed": ["~/scratch"]}
+//
+// A rule covers its directory and everything under it. When both lists
+// cover a path the more specific rule wins, so the example allows
+// ~/scratch and nothing else.
+type targetRules struct {
+	Blocked []string `json:"blocked"`
+	Allowed []string `json:"allowed"`
+}
+
+// loadTargetRules reads the rules at path. A missing file means no rules.
+func loadTargetRules(path string) (targetRules, error) {
+	var rules targetRules
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return rules, err
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return targetRules{}, err
+	}
+	return rules, nil
+}
+
+// normalizePath makes a path from the player or targets.json comparable: ~
+// and environment variables expanded, absolute, with . and .. resolved
+func normalizePath(path string) (string, error) {
+	path, err := expandHome(os.ExpandEnv(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
+}
+
+// coverDepth is how specific rule is about path, -1 if path isn't rule or
+// under it. Any two rules covering the same path are one inside the other,
+// so the longer one is the more specific.
+func coverDepth(rule, path string) int {
+	rel, err := filepath.Rel(rule, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return -1
+	}
+	return len(rule)
+}
+
+// blocked reports whether path is off limits: the most specific rule covering
+// it is a blocked one. Rules that can't be resolved are skipped.
+func (r targetRules) blocked(path string) bool {
+	path, err := normalizePath(path)
+	if err != nil {
+		return false
+	}
+	deepest := func(rules []string) int {
+		d := -1
+		for _, rule := range rules {
+			if rule, err := normalizePath(rule); err == nil {
+				d = max(d, coverDepth(rule, path))
+			}
+		}
+		return d
+	}
+	block := deepest(r.Blocked)
+	return block >= 0 && block >= deepest(r.Allowed)
+}
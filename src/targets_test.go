@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargetRulesBlocked(t *testing.T) {
+	t.Setenv("HOME", "/home/op")
+	rules := targetRules{
+		Blocked: []string{"/etc", "$HOME", "/srv/data/"},
+		Allowed: []string{"~/scratch"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/etc", true},
+		{"/etc/ssh/sshd_config", true},
+		{"/etcetera", false}, // a prefix of the name isn't a prefix of the path
+		{"/home/op", true},
+		{"/home/op/docs", true},
+		{"/home/op/scratch", false},
+		{"/home/op/scratch/tmp", false},
+		{"~/scratch/../docs", true}, // .. climbs back out of the allowed one
+		{"/home/op/./scratch", false},
+		{"/tmp/../etc/passwd", true},
+		{"/srv/data", true}, // trailing slash on the rule
+		{"/srv", false},
+		{"/var/tmp", false},
+	}
+	for _, tt := range tests {
+		if got := rules.blocked(tt.path); got != tt.want {
+			t.Errorf("blocked(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if (targetRules{}).blocked("/") {
+		t.Error("no rules blocked /")
+	}
+}
+
+func TestLoadTargetRules(t *testing.T) {
+	dir := t.TempDir()
+	if rules, err := loadTargetRules(filepath.Join(dir, "missing.json")); err != nil || len(rules.Blocked)+len(rules.Allowed) != 0 {
+		t.Errorf("missing file: %+v, %v, want no rules", rules, err)
+	}
+	path := filepath.Join(dir, "targets.json")
+	if err := os.WriteFile(path, []byte(`{"blocked": ["/"], "allowed": ["/tmp"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := loadTargetRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rules.blocked("/usr") || rules.blocked("/tmp/x") {
+		t.Errorf("loaded rules %+v block the wrong things", rules)
+	}
+}
+
+func TestBlockedTargetRefused(t *testing.T) {
+	dir := t.TempDir()
+	_, err := validateTarget(dir, false, targetRules{Blocked: []string{dir}})
+	if err == nil || !strings.Contains(err.Error(), "BLOCKED") {
+		t.Errorf("blocked target: %v, want a BLOCKED error", err)
+	}
+}
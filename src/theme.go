@@ -0,0 +1,109 @@
+package main
+
+import "image/color"
+
+// Theme is the terminal palette everything on screen is drawn with
+type Theme struct {
+	Name       string
+	Foreground color.RGBA // normal text and highlights
+	Dim        color.RGBA // inactive items
+	Background color.RGBA
+	Warning    color.RGBA // errors and anything dangerous
+	Glow       color.RGBA // background hum, barely above the background
+
+	// Spell things out instead of trusting color: warnings get a !! prefix,
+	// the selected mode gets arrows
+	Markers bool
+}
+
+var themes = []Theme{
+	{"GREEN", hackerGreen, dimGreen, color.RGBA{0, 5, 0, 255}, warningRed, lowGlowGreen, false},
+	{"AMBER", amberAccent, color.RGBA{110, 70, 0, 255}, color.RGBA{8, 4, 0, 255}, warningRed, color.RGBA{50, 30, 0, 255}, false},
+	{"IBM BLUE", color.RGBA{80, 160, 255, 255}, color.RGBA{20, 60, 120, 255}, color.RGBA{0, 2, 12, 255}, warningRed, color.RGBA{0, 20, 50, 255}, false},
+	// For color vision deficiencies, selection and warnings read by brightness and markers alone
+	{"HIGH CONTRAST", color.RGBA{255, 255, 255, 255}, color.RGBA{130, 130, 130, 255}, color.RGBA{0, 0, 0, 255}, color.RGBA{255, 210, 0, 255}, color.RGBA{30, 30, 30, 255}, true},
+}
+
+// themeLock keeps a theme out of the settings until the save has earned it
+type themeLock struct {
+	unlock string // id in unlockRules
+	hint   string // message id telling the player how to earn it
+}
+
+var themeLocks = map[string]themeLock{
+	"AMBER": {unlockAmber, "unlock.win_danger"},
+}
+
+// themeLocked reports whether themes[i] is still locked for save, and if so
+// the hint for unlocking it
+func themeLocked(save *SaveData, i int) (string, bool) {
+	lock, ok := themeLocks[themes[i].Name]
+	if !ok || save.unlocked(lock.unlock) {
+		return "", false
+	}
+	return lock.hint, true
+}
+
+// themeIndex finds a theme by name, falling back to the first (GREEN)
+func themeIndex(name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// theme is the palette currently selected in the settings. A locked theme
+// in a hand edited save falls back to GREEN.
+func (g *Game) theme() Theme {
+	i := themeIndex(g.save.Settings.Theme)
+	if _, locked := themeLocked(g.save, i); locked {
+		i = 0
+	}
+	return themes[i]
+}
+
+// selectTheme switches to themes[i], refusing if it's still locked
+func (g *Game) selectTheme(i int) bool {
+	if _, locked := themeLocked(g.save, i); locked {
+		return false
+	}
+	g.save.Settings.Theme = themes[i].Name
+	g.terminalColor = themes[i].Foreground
+	return true
+}
+
+// cycleTheme steps the settings' theme cursor forwards or backwards. Locked
+// themes can be looked at but the palette stays on the last unlocked one.
+func (g *Game) cycleTheme(step int) {
+	g.themeCursor = (g.themeCursor + step + len(themes)) % len(themes)
+	g.selectTheme(g.themeCursor)
+}
+
+// lerpColor blends from a to b, t is 0..1
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// warn marks s as a warning in themes that don't rely on color for it
+func (g *Game) warn(s string) string {
+	if g.theme().Markers {
+		return "!! " + s
+	}
+	return s
+}
+
+// modeLabel is how a mode reads in the menu's selector. The selected one is
+// bracketed, or arrowed in themes with markers, and every label is the same
+// width either way so the row doesn't shift.
+func modeLabel(name string, selected, markers bool) string {
+	switch {
+	case !selected:
+		return "  " + name + "  "
+	case markers:
+		return "> " + name + " <"
+	}
+	return "[ " + name + " ]"
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSwitchThemeChangesForeground(t *testing.T) {
+	g, _ := testGame(t)
+	if got := g.theme().Foreground; got != hackerGreen {
+		t.Fatalf("default foreground %v, want hacker green", got)
+	}
+
+	// AMBER is still locked on a fresh save, so the palette stays put
+	g.cycleTheme(1)
+	if got := g.theme().Foreground; got != hackerGreen {
+		t.Errorf("locked AMBER changed the foreground to %v", got)
+	}
+
+	g.cycleTheme(1)
+	blue := themes[themeIndex("IBM BLUE")].Foreground
+	if got := g.theme().Foreground; got != blue {
+		t.Errorf("IBM BLUE: foreground %v, want %v", got, blue)
+	}
+	if g.terminalColor != blue {
+		t.Errorf("terminal color %v didn't follow the theme", g.terminalColor)
+	}
+	if g.save.Settings.Theme != "IBM BLUE" {
+		t.Errorf("saved theme %q, want IBM BLUE", g.save.Settings.Theme)
+	}
+}
+
+func TestSelectedModeReadsWithoutColor(t *testing.T) {
+	for _, markers := range []bool{false, true} {
+		for _, name := range modeNames {
+			plain, picked := modeLabel(name, false, markers), modeLabel(name, true, markers)
+			if plain == picked {
+				t.Errorf("markers %v: %s reads the same selected or not", markers, name)
+			}
+			if len(plain) != len(picked) {
+				t.Errorf("markers %v: %s label changes width from %q to %q", markers, name, plain, picked)
+			}
+		}
+	}
+	if got := modeLabel("SAFE", true, true); !strings.HasPrefix(got, "> ") {
+		t.Errorf("high contrast selection %q, want a > prefix", got)
+	}
+}
+
+func TestHighContrastWarnings(t *testing.T) {
+	g, _ := testGame(t)
+	if got := g.warn("ALARM"); got != "ALARM" {
+		t.Errorf("GREEN warning %q, want it left to color", got)
+	}
+	if !g.selectTheme(themeIndex("HIGH CONTRAST")) {
+		t.Fatal("HIGH CONTRAST is locked")
+	}
+	if !g.theme().Markers {
+		t.Fatal("HIGH CONTRAST doesn't use markers")
+	}
+	if got := g.warn("ALARM"); got != "!! ALARM" {
+		t.Errorf("HIGH CONTRAST warning %q, want a !! prefix", got)
+	}
+}
+
+func TestLerpColorEndpoints(t *testing.T) {
+	a, b := color.RGBA{0, 100, 200, 255}, color.RGBA{200, 0, 100, 0}
+	if got := lerpColor(a, b, 0); got != a {
+		t.Errorf("t=0 gave %v, want %v", got, a)
+	}
+	if got := lerpColor(a, b, 1); got != b {
+		t.Errorf("t=1 gave %v, want %v", got, b)
+	}
+	if got, want := lerpColor(a, b, 0.5), (color.RGBA{100, 50, 150, 127}); got != want {
+		t.Errorf("t=0.5 gave %v, want %v", got, want)
+	}
+}
+
+func TestAmberUnlocksWithDangerWin(t *testing.T) {
+	g, _ := testGame(t)
+	amber := themeIndex("AMBER")
+	hint, locked := themeLocked(g.save, amber)
+	if !locked || hint != "unlock.win_danger" {
+		t.Fatalf("fresh save: AMBER locked %v hint %q", locked, hint)
+	}
+	if g.selectTheme(amber) {
+		t.Error("selecting locked AMBER was allowed")
+	}
+	if g.save.Settings.Theme == "AMBER" {
+		t.Error("the locked theme made it into the settings")
+	}
+
+	// A lost DANGER run or a win in another mode doesn't count
+	g.save.recordRun(ModeDanger, false, time.Minute, 10, 0)
+	g.save.recordRun(ModeSafe, true, time.Minute, 10, 0)
+	if _, locked := themeLocked(g.save, amber); !locked {
+		t.Fatal("AMBER unlocked without a DANGER win")
+	}
+
+	g.save.recordRun(ModeDanger, true, time.Minute, 10, 0)
+	if _, locked := themeLocked(g.save, amber); locked {
+		t.Fatal("a DANGER win didn't unlock AMBER")
+	}
+	if !g.selectTheme(amber) || g.theme().Name != "AMBER" {
+		t.Errorf("unlocked AMBER couldn't be selected, theme is %s", g.theme().Name)
+	}
+}
+
+func TestLockedThemeInSaveFallsBack(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.Settings.Theme = "AMBER" // hand edited
+	if got := g.theme().Name; got != "GREEN" {
+		t.Errorf("locked theme in the save shows as %s, want GREEN", got)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"image/color"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+)
+
+const (
+	toastTime = 1500 * time.Millisecond // how long the usual notices stay up
+	toastFade = 400 * time.Millisecond  // the tail end of a toast's time it spends fading out
+)
+
+// toast is a short notice stacked in the bottom right corner
+type toast struct {
+	msg     string
+	expires time.Time
+}
+
+// pushToast shows msg for dur, underneath any toasts already up
+func (g *Game) pushToast(msg string, dur time.Duration) {
+	g.toasts = append(g.toasts, toast{msg, g.now().Add(dur)})
+}
+
+// pushToastOnce is pushToast, unless the same message is already up
+func (g *Game) pushToastOnce(msg string, dur time.Duration) {
+	if slices.ContainsFunc(g.toasts, func(t toast) bool { return t.msg == msg }) {
+		return
+	}
+	g.pushToast(msg, dur)
+}
+
+// expireToasts drops every toast whose time is up
+func (g *Game) expireToasts() {
+	now := g.now()
+	g.toasts = slices.DeleteFunc(g.toasts, func(t toast) bool { return !now.Before(t.expires) })
+}
+
+// toastAlpha is how opaque a toast is, fading out over its last toastFade.
+// With reduced motion it stays solid and just goes.
+func (g *Game) toastAlpha(t toast) float64 {
+	left := t.expires.Sub(g.now())
+	if g.save.Settings.ReducedMotion || left >= toastFade {
+		return 1
+	}
+	return max(0, float64(left)/float64(toastFade))
+}
+
+// drawToasts stacks the toasts up from the bottom right, oldest on top
+func (g *Game) drawToasts(screen *ebiten.Image) {
+	pad := g.marginX() / 2
+	ascent := mplusNormalFont.Metrics().Ascent.Ceil()
+	y := g.screenHeight - g.marginY() - len(g.toasts)*(lineHeight()+pad)
+	for _, t := range g.toasts {
+		a := g.toastAlpha(t)
+		w := font.MeasureString(mplusNormalFont, t.msg).Ceil()
+		x := g.screenWidth - g.marginX() - w
+		vector.FillRect(screen, float32(x-pad), float32(y), float32(w+2*pad), float32(lineHeight()), scaleColor(color.RGBA{0, 0, 0, 255}, 0.8*a), false)
+		text.Draw(screen, t.msg, mplusNormalFont, x, y+ascent+(lineHeight()-ascent)/3, scaleColor(g.theme().Foreground, a))
+		y += lineHeight() + pad
+	}
+}
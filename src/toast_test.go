@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// toastMsgs is the messages currently up, oldest first
+func toastMsgs(g *Game) []string {
+	var msgs []string
+	for _, t := range g.toasts {
+		msgs = append(msgs, t.msg)
+	}
+	return msgs
+}
+
+func TestToastsExpire(t *testing.T) {
+	g, _ := testGame(t)
+	g.pushToast("short", 500*time.Millisecond)
+	g.pushToast("long", 2*time.Second)
+	g.pushToastOnce("long", time.Hour) // already up, not stacked again
+	if got := toastMsgs(g); len(got) != 2 {
+		t.Fatalf("toasts %q, want short and long", got)
+	}
+
+	update(t, g, int(500*time.Millisecond/tickLength())+1)
+	if got := toastMsgs(g); len(got) != 1 || got[0] != "long" {
+		t.Fatalf("toasts %q after half a second, want just long", got)
+	}
+	update(t, g, int(1500*time.Millisecond/tickLength())+1)
+	if len(g.toasts) != 0 {
+		t.Errorf("toasts %q after they all ran out", toastMsgs(g))
+	}
+}
+
+func TestToastFade(t *testing.T) {
+	g, _ := testGame(t)
+	g.pushToast("hi", time.Second)
+	tst := g.toasts[0]
+	if a := g.toastAlpha(tst); a != 1 {
+		t.Errorf("fresh toast alpha %v, want 1", a)
+	}
+	update(t, g, int(800*time.Millisecond/tickLength()))
+	if a := g.toastAlpha(tst); a <= 0 || a >= 1 {
+		t.Errorf("toast alpha %v near the end, want it fading", a)
+	}
+	g.save.Settings.ReducedMotion = true
+	if a := g.toastAlpha(tst); a != 1 {
+		t.Errorf("reduced motion alpha %v, want it solid until it goes", a)
+	}
+}
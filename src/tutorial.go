@@ -0,0 +1,41 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Message ids of the tutorial shown over the menu on the first launch
+var tutorialLines = []string{
+	"tutorial.title",
+	"",
+	"tutorial.modes",
+	"tutorial.prompt",
+	"tutorial.goal",
+	"tutorial.help",
+	"",
+	"tutorial.dismiss",
+}
+
+// tutorialShowing is whether the tutorial is up. It only covers the mode
+// picker, once the player is typing or confirming they've found their way.
+func (g *Game) tutorialShowing() bool {
+	return g.state == StateMenu && !g.save.TutorialSeen && !g.inputActive && !g.confirmActive
+}
+
+// updateTutorial waits for the tutorial to be dismissed, then remembers that
+// in the save so it stays gone
+func (g *Game) updateTutorial() {
+	if g.justPressed(ActionConfirm) || g.justPressed(ActionCancel) {
+		g.save.TutorialSeen = true
+		g.writeSaveFile()
+		g.lastInputTime = g.now() // don't go straight into attract mode
+	}
+}
+
+func (g *Game) drawTutorial(screen *ebiten.Image) {
+	lines := make([]string, len(tutorialLines))
+	for i, id := range tutorialLines {
+		if id != "" {
+			lines[i] = tr(id)
+		}
+	}
+	g.drawPanel(screen, lines)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestTutorialDismissalIsSaved(t *testing.T) {
+	g, in := testGame(t)
+	g.save.TutorialSeen = false // first launch
+	g.savePath = filepath.Join(t.TempDir(), "save.json")
+	if !g.tutorialShowing() {
+		t.Fatal("tutorial isn't up on the first launch")
+	}
+
+	// The tutorial swallows input meant for the menu underneath
+	tap(t, g, in, ebiten.KeyRight)
+	if g.currentMode != ModeSafe || !g.tutorialShowing() {
+		t.Fatalf("right under the tutorial moved to %v", modeNames[g.currentMode])
+	}
+
+	tap(t, g, in, ebiten.KeyEnter)
+	if g.tutorialShowing() {
+		t.Fatal("enter didn't dismiss the tutorial")
+	}
+	if g.inputActive {
+		t.Error("the dismissing enter also opened the prompt")
+	}
+	saved, err := loadSave(g.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.TutorialSeen {
+		t.Error("dismissing the tutorial wasn't saved")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "time"
+
+// maxTicksPerSecond caps the typewriter tick, a fast reveal puts out several
+// characters a frame and ticking for every one would just be a buzz
+const maxTicksPerSecond = 25
+
+// tickLimiter lets through at most one event per interval
+type tickLimiter struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func newTickLimiter(perSecond int) tickLimiter {
+	return tickLimiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+// allow reports whether an event at now gets through, and if so counts it
+func (l *tickLimiter) allow(now time.Time) bool {
+	if now.Sub(l.last) < l.interval {
+		return false
+	}
+	l.last = now
+	return true
+}
+
+// typewriterTick plays the teletype tick for revealed or typed characters,
+// unless it's turned off or went off too recently
+func (g *Game) typewriterTick() {
+	if g.save.Settings.DisableTicks || !g.tickLimit.allow(g.now()) {
+		return
+	}
+	g.playSound(teletypeSound)
+}
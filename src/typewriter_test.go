@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ticksIn is how many of a burst of events, one every step for dur, the
+// limiter lets through
+func ticksIn(l *tickLimiter, start time.Time, step, dur time.Duration) int {
+	n := 0
+	for at := time.Duration(0); at < dur; at += step {
+		if l.allow(start.Add(at)) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestTickLimiterCapsPerSecond(t *testing.T) {
+	for _, c := range []struct {
+		perSecond int
+		step      time.Duration
+		want      int
+	}{
+		// Far faster than the cap, so exactly the cap gets through
+		{25, time.Millisecond, 25},
+		{10, time.Millisecond, 10},
+		// Just too quick for the cap, so only every other one
+		{25, 30 * time.Millisecond, 17},
+		// Slower than the cap, every one gets through
+		{25, 100 * time.Millisecond, 10},
+	} {
+		l := newTickLimiter(c.perSecond)
+		if got := ticksIn(&l, gameEpoch, c.step, time.Second); got != c.want {
+			t.Errorf("%d/s limiter with an event every %v let %d through in a second, want %d", c.perSecond, c.step, got, c.want)
+		}
+	}
+}
+
+func TestTickLimiterFirstEvent(t *testing.T) {
+	l := newTickLimiter(maxTicksPerSecond)
+	if !l.allow(gameEpoch) {
+		t.Error("the very first tick was held back")
+	}
+	if l.allow(gameEpoch.Add(time.Millisecond)) {
+		t.Error("a tick 1ms later got through")
+	}
+}
+
+func TestTypewriterTickOff(t *testing.T) {
+	g, _ := testGame(t)
+	g.save.Settings.DisableTicks = true
+	g.typewriterTick()
+	if !g.tickLimit.last.IsZero() {
+		t.Error("a disabled tick still counted against the limiter")
+	}
+	g.save.Settings.DisableTicks = false
+	g.typewriterTick()
+	if !g.tickLimit.last.Equal(g.now()) {
+		t.Error("an enabled tick wasn't counted")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Dry run deletions only happen to the model, so they can be taken back.
+// Real ones can't, nothing gets pushed for those.
+const undoLimit = 20 // oldest deletions fall off past this
+
+// deletion is one rm, enough to put the node back where it was
+type deletion struct {
+	node   FSNode
+	index  int // position in fsNodes it was removed from
+	points int // what the rm scored, handed back on undo
+}
+
+// pushUndo remembers a deletion, dropping the oldest once the stack is full
+func (g *Game) pushUndo(d deletion) {
+	g.undoStack = append(g.undoStack, d)
+	if len(g.undoStack) > undoLimit {
+		g.undoStack = slices.Delete(g.undoStack, 0, len(g.undoStack)-undoLimit)
+	}
+}
+
+// undoPressed is Ctrl+Z (or Cmd+Z)
+func (g *Game) undoPressed() bool {
+	return g.input.JustPressed(ebiten.KeyZ) && (g.input.Pressed(ebiten.KeyControl) || g.input.Pressed(ebiten.KeyMeta))
+}
+
+// restoreNode puts a deleted node back into the model where it was
+func (g *Game) restoreNode(d deletion) {
+	g.fsMutex.Lock()
+	defer g.fsMutex.Unlock()
+	i := min(d.index, len(g.fsNodes))
+	g.fsNodes = slices.Insert(g.fsNodes, i, d.node)
+	g.nodesChanged()
+	g.fsStats.add(d.node, 1)
+}
+
+// undoDelete puts the most recently deleted node back and takes its points
+// away again, so rm and undo can't be farmed. With nothing to undo it does
+// nothing and returns false.
+func (g *Game) undoDelete() bool {
+	if len(g.undoStack) == 0 {
+		return false
+	}
+	d := g.undoStack[len(g.undoStack)-1]
+	g.undoStack = g.undoStack[:len(g.undoStack)-1]
+
+	g.restoreNode(d)
+	g.score -= d.points
+	g.combo = 0
+	g.echo(fmt.Sprintf(tr("console.restored"), filepath.Base(d.node.Path)))
+	return true
+}
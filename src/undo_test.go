@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestUndoRestoresDeletion(t *testing.T) {
+	g, in := testGame(t)
+	startRun(t, g, ModeDestruction, diskFS{}, realTree(t, victimFiles))
+	before, stats := slices.Clone(g.fsNodes), g.fsStats
+
+	path := victim(t, g)
+	g.cmdRm([]string{filepath.Base(path)})
+	if g.findNode(path) >= 0 || g.score == 0 {
+		t.Fatalf("rm didn't go through: %q", g.commandOutput)
+	}
+
+	in.press(ebiten.KeyControl)
+	tap(t, g, in, ebiten.KeyZ)
+	in.release(ebiten.KeyControl)
+	if !slices.EqualFunc(g.fsNodes, before, func(a, b FSNode) bool { return a.Path == b.Path }) {
+		t.Error("undo didn't put the node back where it was")
+	}
+	if g.fsStats != stats || g.score != 0 {
+		t.Errorf("after undo stats %+v score %d, want %+v and 0", g.fsStats, g.score, stats)
+	}
+
+	// Nothing left to undo
+	if g.undoDelete() {
+		t.Error("undo with an empty stack did something")
+	}
+	if len(g.fsNodes) != len(before) {
+		t.Errorf("undo past the bottom changed the model to %d nodes", len(g.fsNodes))
+	}
+}
+
+func TestUndoStackIsCapped(t *testing.T) {
+	g, _ := testGame(t)
+	for i := range undoLimit + 5 {
+		g.pushUndo(deletion{index: i})
+	}
+	if len(g.undoStack) != undoLimit {
+		t.Fatalf("undo stack holds %d, want %d", len(g.undoStack), undoLimit)
+	}
+	if g.undoStack[0].index != 5 {
+		t.Errorf("oldest kept deletion is %d, want 5", g.undoStack[0].index)
+	}
+}
+
+func TestRealDeletionCantBeUndone(t *testing.T) {
+	g, _ := testGame(t)
+	g.dryRun = false
+	startRun(t, g, ModeDestruction, diskFS{}, realTree(t, victimFiles))
+	g.cmdRm([]string{filepath.Base(victim(t, g))})
+	if len(g.undoStack) != 0 {
+		t.Errorf("a real delete pushed %d undo entries", len(g.undoStack))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"image"
+	"image/png"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed icon.png
+var iconData []byte
+
+const baseWindowTitle = "Termi-War"
+
+// windowTitle is what the taskbar shows: the game, then the mode and what's
+// going on. There's no mode to speak of while booting.
+func windowTitle(mode Mode, state GameState) string {
+	if state == StateBooting {
+		return baseWindowTitle + " — " + state.String()
+	}
+	return baseWindowTitle + " — " + modeNames[mode] + " — " + state.String()
+}
+
+// updateWindowTitle keeps the title in step with the game, only calling
+// into Ebiten when it actually changes
+func (g *Game) updateWindowTitle() {
+	title := windowTitle(g.currentMode, g.state)
+	if g.bossActive {
+		title = bossTitle
+	}
+	if title == g.windowTitle {
+		return
+	}
+	ebiten.SetWindowTitle(title)
+	g.windowTitle = title
+}
+
+// setWindowIcon uses the embedded icon, a broken one just leaves the default
+func setWindowIcon() {
+	icon, err := png.Decode(bytes.NewReader(iconData))
+	if err != nil {
+		log.Println("bad window icon:", err)
+		return
+	}
+	ebiten.SetWindowIcon([]image.Image{icon})
+}
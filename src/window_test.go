@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestWindowTitle(t *testing.T) {
+	for _, c := range []struct {
+		mode  Mode
+		state GameState
+		want  string
+	}{
+		{ModeDanger, StatePlaying, "Termi-War — DANGER — PLAYING"},
+		{ModeSafe, StateMenu, "Termi-War — SAFE — MENU"},
+		{ModeDestruction, StateLoose, "Termi-War — DESTRUCTION — LOST"},
+		{ModeDanger, StateBooting, "Termi-War — BOOTING"},
+	} {
+		if got := windowTitle(c.mode, c.state); got != c.want {
+			t.Errorf("windowTitle(%v, %v) = %q, want %q", c.mode, c.state, got, c.want)
+		}
+	}
+}
+
+func TestWindowTitleFollowsGame(t *testing.T) {
+	g, _ := testGame(t)
+	g.currentMode = ModeDanger
+	g.state = StatePaused
+	g.updateWindowTitle()
+	if g.windowTitle != "Termi-War — DANGER — PAUSED" {
+		t.Errorf("title is %q", g.windowTitle)
+	}
+	g.bossActive = true
+	g.updateWindowTitle()
+	if g.windowTitle != bossTitle {
+		t.Errorf("boss key left the title as %q", g.windowTitle)
+	}
+}
+
+func TestWindowIconDecodes(t *testing.T) {
+	if _, err := png.Decode(bytes.NewReader(iconData)); err != nil {
+		t.Fatal("embedded icon:", err)
+	}
+}